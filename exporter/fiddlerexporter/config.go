@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+)
+
+const (
+	envTypeProduction    = "PRODUCTION"
+	envTypePreProduction = "PRE_PRODUCTION"
+)
+
+// PublishConfig defines where converted events are published.
+type PublishConfig struct {
+	// ModelID is the Fiddler model that events are published to when the
+	// telemetry does not carry the ModelIDAttribute.
+	ModelID string `mapstructure:"model_id"`
+	// ModelIDAttribute is the record or resource attribute holding the
+	// ID of the Fiddler model the event belongs to.
+	ModelIDAttribute string `mapstructure:"model_id_attribute"`
+	// TimestampColumn, when set, adds the telemetry timestamp to every
+	// event under this column name.
+	TimestampColumn string `mapstructure:"timestamp_column"`
+	// EnvType is the Fiddler environment events are published to.
+	EnvType string `mapstructure:"env_type"`
+}
+
+func (cfg PublishConfig) validate() error {
+	if cfg.ModelID == "" && cfg.ModelIDAttribute == "" {
+		return errors.New("either model_id or model_id_attribute must be set")
+	}
+	if cfg.EnvType != envTypeProduction && cfg.EnvType != envTypePreProduction {
+		return fmt.Errorf("invalid env_type %q: must be %q or %q", cfg.EnvType, envTypeProduction, envTypePreProduction)
+	}
+	return nil
+}
+
+func (cfg PublishConfig) translatorSettings() translator.Settings {
+	return translator.Settings{
+		ModelID:          cfg.ModelID,
+		ModelIDAttribute: cfg.ModelIDAttribute,
+		TimestampColumn:  cfg.TimestampColumn,
+	}
+}
+
+// LogsConfig defines how log records are published as Fiddler events.
+type LogsConfig struct {
+	PublishConfig `mapstructure:",squash"`
+
+	_ struct{}
+}
+
+func (cfg LogsConfig) Validate() error {
+	return cfg.validate()
+}
+
+// MetricsConfig defines how metric data points are published to Fiddler,
+// where they can be used as inputs to custom metrics.
+type MetricsConfig struct {
+	PublishConfig `mapstructure:",squash"`
+	// Include lists the names of the metrics to publish. All metrics are
+	// published when empty.
+	Include []string `mapstructure:"include"`
+
+	_ struct{}
+}
+
+func (cfg MetricsConfig) Validate() error {
+	return cfg.validate()
+}
+
+func (cfg MetricsConfig) translatorSettings() translator.MetricsSettings {
+	return translator.MetricsSettings{
+		Settings: cfg.PublishConfig.translatorSettings(),
+		Include:  cfg.Include,
+	}
+}
+
+// TracesConfig defines how GenAI spans are published to Fiddler as LLM
+// application events.
+type TracesConfig struct {
+	PublishConfig `mapstructure:",squash"`
+
+	_ struct{}
+}
+
+func (cfg TracesConfig) Validate() error {
+	return cfg.validate()
+}
+
+// CircuitBreakerConfig defines when requests to Fiddler are suspended after
+// repeated failures.
+type CircuitBreakerConfig struct {
+	// Enabled turns the circuit breaker on.
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold is the number of consecutive failed requests that
+	// open the circuit breaker.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// Cooldown is how long requests are suspended once the circuit breaker
+	// opened, before a single probe request is sent.
+	Cooldown time.Duration `mapstructure:"cooldown"`
+
+	_ struct{}
+}
+
+func (cfg CircuitBreakerConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.FailureThreshold <= 0 {
+		return errors.New("failure_threshold must be greater than 0")
+	}
+	if cfg.Cooldown <= 0 {
+		return errors.New("cooldown must be greater than 0")
+	}
+	return nil
+}
+
+// DebugConfig defines troubleshooting options.
+type DebugConfig struct {
+	// LogPayloads logs requests sent to Fiddler and their responses at debug
+	// level, with the token redacted.
+	LogPayloads bool `mapstructure:"log_payloads"`
+
+	_ struct{}
+}
+
+// Config defines configuration for the Fiddler exporter.
+type Config struct {
+	ClientConfig confighttp.ClientConfig         `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	RetryConfig  configretry.BackOffConfig       `mapstructure:"retry_on_failure"`
+	QueueConfig  exporterhelper.QueueBatchConfig `mapstructure:"sending_queue"`
+
+	// FailoverEndpoints are URLs of the same logical Fiddler deployment,
+	// e.g. a disaster recovery replica, used in order when the endpoint is
+	// unavailable.
+	FailoverEndpoints []string `mapstructure:"failover_endpoints"`
+
+	// Fiddler API token. Optional when auth is configured.
+	Token configopaque.String `mapstructure:"token"`
+
+	// ValidateToken checks the token against the Fiddler API at startup, so
+	// that an invalid token fails the collector start instead of every export.
+	ValidateToken bool `mapstructure:"validate_token"`
+
+	Logs           LogsConfig           `mapstructure:"logs"`
+	Metrics        MetricsConfig        `mapstructure:"metrics"`
+	Traces         TracesConfig         `mapstructure:"traces"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Debug          DebugConfig          `mapstructure:"debug"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.ClientConfig.Endpoint == "" {
+		return errMissingEndpoint
+	}
+	if _, err := normalizeEndpoint(cfg.ClientConfig.Endpoint); err != nil {
+		return err
+	}
+	for _, endpoint := range cfg.FailoverEndpoints {
+		if _, err := normalizeEndpoint(endpoint); err != nil {
+			return fmt.Errorf("invalid failover endpoint: %w", err)
+		}
+	}
+	// Requests are authenticated by the auth extension instead, e.g. with
+	// OAuth2 client credentials for deployments behind an identity-aware proxy.
+	if cfg.Token == "" && !cfg.ClientConfig.Auth.HasValue() {
+		return errMissingToken
+	}
+	return nil
+}
+
+// normalizeEndpoint returns endpoint with the https scheme when it has none,
+// and without trailing slashes since API paths are appended to it.
+func normalizeEndpoint(endpoint string) (string, error) {
+	normalized := endpoint
+	if !strings.Contains(normalized, "://") {
+		normalized = "https://" + normalized
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("endpoint must be a valid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("endpoint must have http or https scheme: %q", endpoint)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("endpoint must have a host: %q", endpoint)
+	}
+	// An empty query or fragment, as in "https://app.fiddler.ai/?", is not
+	// visible in u, but would swallow the API paths appended to the endpoint.
+	if strings.ContainsAny(endpoint, "?#") {
+		return "", fmt.Errorf("endpoint must not have a query or fragment: %q", endpoint)
+	}
+	return strings.TrimRight(normalized, "/"), nil
+}
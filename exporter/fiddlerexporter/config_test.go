@@ -0,0 +1,266 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		id           component.ID
+		expected     *Config
+		errorMessage string
+	}{
+		{
+			id: component.NewIDWithName(metadata.Type, ""),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.RetryConfig.Enabled = false
+				cfg.QueueConfig.Enabled = false
+				cfg.Token = "test-token"
+				cfg.ValidateToken = false
+				cfg.Logs = LogsConfig{
+					PublishConfig: PublishConfig{
+						ModelID:          "credit-model",
+						ModelIDAttribute: "ml.model.id",
+						TimestampColumn:  "event_time",
+						EnvType:          "PRE_PRODUCTION",
+					},
+				}
+				cfg.Metrics = MetricsConfig{
+					PublishConfig: PublishConfig{
+						ModelID:          "credit-model-kpis",
+						ModelIDAttribute: "fiddler.model.id",
+						EnvType:          "PRODUCTION",
+					},
+					Include: []string{"http.server.request.duration", "loan.amount"},
+				}
+				cfg.Traces = TracesConfig{
+					PublishConfig: PublishConfig{
+						ModelID:          "support-chatbot",
+						ModelIDAttribute: "fiddler.model.id",
+						TimestampColumn:  "event_time",
+						EnvType:          "PRODUCTION",
+					},
+				}
+				cfg.CircuitBreaker = CircuitBreakerConfig{
+					Enabled:          true,
+					FailureThreshold: 3,
+					Cooldown:         30 * time.Second,
+				}
+				cfg.Debug.LogPayloads = true
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "tls"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://fiddler.internal.example.com"
+				cfg.ClientConfig.TLS = configtls.ClientConfig{
+					Config: configtls.Config{
+						CAFile:   "/etc/ssl/fiddler-ca.pem",
+						CertFile: "/etc/ssl/client.pem",
+						KeyFile:  "/etc/ssl/client-key.pem",
+					},
+				}
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "host_override"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://10.20.30.40"
+				cfg.ClientConfig.Headers = map[string]configopaque.String{
+					"Host": "fiddler.internal.example.com",
+				}
+				cfg.ClientConfig.TLS.ServerName = "fiddler.internal.example.com"
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "proxy"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.ClientConfig.ProxyURL = "http://proxy.corp.example.com:3128"
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "headers"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.ClientConfig.Headers = map[string]configopaque.String{
+					"X-Tenant-ID": "risk-team",
+					"User-Agent":  "acme-collector/1.0",
+				}
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "connection_pool"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.ClientConfig.MaxIdleConns = 50
+				cfg.ClientConfig.MaxIdleConnsPerHost = 20
+				cfg.ClientConfig.MaxConnsPerHost = 40
+				cfg.ClientConfig.IdleConnTimeout = 5 * time.Minute
+				cfg.Token = "test-token"
+				return cfg
+			}(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "failover"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://fiddler.us-east.example.com"
+				cfg.FailoverEndpoints = []string{"https://fiddler.us-west.example.com"}
+				cfg.Token = "test-token"
+				cfg.CircuitBreaker.Enabled = true
+				return cfg
+			}(),
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_env_type"),
+			errorMessage: `logs: invalid env_type "STAGING": must be "PRODUCTION" or "PRE_PRODUCTION"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "missing_model"),
+			errorMessage: "logs: either model_id or model_id_attribute must be set\nmetrics: either model_id or model_id_attribute must be set\ntraces: either model_id or model_id_attribute must be set",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_circuit_breaker"),
+			errorMessage: "circuit_breaker: failure_threshold must be greater than 0",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_failover_endpoint"),
+			errorMessage: `invalid failover endpoint: endpoint must not have a query or fragment: "https://fiddler.us-west.example.com?region=us-west"`,
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "oauth2"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+				cfg.ClientConfig.Auth = configoptional.Some(configauth.Config{
+					AuthenticatorID: component.MustNewID("oauth2client"),
+				})
+				return cfg
+			}(),
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "missing_token"),
+			errorMessage: "missing Fiddler API token",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "missing_endpoint"),
+			errorMessage: "missing Fiddler API endpoint",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_endpoint"),
+			errorMessage: `endpoint must have http or https scheme: "ftp://app.fiddler.ai"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expected == nil {
+				assert.EqualError(t, xconfmap.Validate(cfg), tt.errorMessage)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestConfigTokenIsMasked(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = "https://app.fiddler.ai"
+	cfg.Token = "secret-token"
+
+	conf := confmap.New()
+	require.NoError(t, conf.Marshal(cfg))
+	assert.Equal(t, "[REDACTED]", conf.Get("token"))
+	assert.NotContains(t, fmt.Sprintf("%v", conf.ToStringMap()), "secret-token")
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  string
+	}{
+		{endpoint: "https://app.fiddler.ai", want: "https://app.fiddler.ai"},
+		{endpoint: "app.fiddler.ai", want: "https://app.fiddler.ai"},
+		{endpoint: "fiddler.internal:8443//", want: "https://fiddler.internal:8443"},
+		{endpoint: "http://localhost:8080/fiddler/", want: "http://localhost:8080/fiddler"},
+		{endpoint: "ftp://app.fiddler.ai", wantErr: `endpoint must have http or https scheme: "ftp://app.fiddler.ai"`},
+		{endpoint: "https://", wantErr: `endpoint must have a host: "https://"`},
+		{endpoint: "https://app.fiddler.ai/#v3", wantErr: `endpoint must not have a query or fragment: "https://app.fiddler.ai/#v3"`},
+		{endpoint: "https://app.fiddler.ai/?", wantErr: `endpoint must not have a query or fragment: "https://app.fiddler.ai/?"`},
+		{endpoint: "https://app.fiddler.ai?", wantErr: `endpoint must not have a query or fragment: "https://app.fiddler.ai?"`},
+		{endpoint: "https://app.fiddler.ai/#", wantErr: `endpoint must not have a query or fragment: "https://app.fiddler.ai/#"`},
+		{endpoint: "https://app.fiddler.ai?region=us", wantErr: `endpoint must not have a query or fragment: "https://app.fiddler.ai?region=us"`},
+		{endpoint: "https://app.fiddler.ai:port", wantErr: "endpoint must be a valid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := normalizeEndpoint(tt.endpoint)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
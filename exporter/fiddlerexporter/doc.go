@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package fiddlerexporter defines the Fiddler exporter,
+// which publishes model inference events to Fiddler.
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
@@ -0,0 +1,14 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
+
+import "errors"
+
+var (
+	// errMissingToken indicates that the Fiddler API token is missing.
+	errMissingToken = errors.New("missing Fiddler API token")
+
+	// errMissingEndpoint indicates that the Fiddler API endpoint is missing.
+	errMissingEndpoint = errors.New("missing Fiddler API endpoint")
+)
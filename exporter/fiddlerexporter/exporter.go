@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+type fiddlerExporter struct {
+	config    *Config
+	client    *fiddler.Client
+	telemetry *metadata.TelemetryBuilder
+	logger    *zap.Logger
+	settings  component.TelemetrySettings
+	userAgent string
+}
+
+func newExporter(cfg component.Config, set exporter.Settings) *fiddlerExporter {
+	oCfg := cfg.(*Config)
+
+	userAgent := fmt.Sprintf("%s/%s (%s/%s)",
+		set.BuildInfo.Description, set.BuildInfo.Version, runtime.GOOS, runtime.GOARCH)
+
+	return &fiddlerExporter{
+		config:    oCfg,
+		logger:    set.Logger,
+		userAgent: userAgent,
+		settings:  set.TelemetrySettings,
+	}
+}
+
+func (e *fiddlerExporter) Start(ctx context.Context, host component.Host) error {
+	httpClient, err := e.config.ClientConfig.ToClient(ctx, host, e.settings)
+	if err != nil {
+		return err
+	}
+	e.telemetry, err = metadata.NewTelemetryBuilder(e.settings)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := normalizeEndpoint(e.config.ClientConfig.Endpoint)
+	if err != nil {
+		return err
+	}
+	failoverEndpoints := make([]string, 0, len(e.config.FailoverEndpoints))
+	for _, failoverEndpoint := range e.config.FailoverEndpoints {
+		normalized, err := normalizeEndpoint(failoverEndpoint)
+		if err != nil {
+			return err
+		}
+		failoverEndpoints = append(failoverEndpoints, normalized)
+	}
+
+	opts := []fiddler.Option{
+		fiddler.WithTelemetry(clientTelemetry{builder: e.telemetry}),
+		fiddler.WithFailoverEndpoints(failoverEndpoints...),
+	}
+	if e.config.ClientConfig.Timeout > 0 {
+		// The exporterhelper timeout is disabled, so bound the time spent
+		// waiting for a rate limit to reset the same way as requests.
+		opts = append(opts, fiddler.WithMaxRateLimitWait(e.config.ClientConfig.Timeout))
+	}
+	if e.config.CircuitBreaker.Enabled {
+		opts = append(opts, fiddler.WithCircuitBreaker(e.config.CircuitBreaker.FailureThreshold, e.config.CircuitBreaker.Cooldown))
+	}
+	if e.config.Debug.LogPayloads {
+		opts = append(opts, fiddler.WithPayloadLogging(e.logger))
+	}
+	e.client = fiddler.New(httpClient, endpoint, e.config.Token, e.userAgent, opts...)
+
+	if e.config.ValidateToken {
+		if err := e.client.ValidateToken(ctx); err != nil {
+			if errors.Is(err, fiddler.ErrUnauthorized) {
+				return fmt.Errorf("failed to validate Fiddler API token: %w", err)
+			}
+			// Fiddler may be temporarily unreachable, which the retry
+			// mechanism handles once data is exported.
+			e.logger.Warn("Could not validate Fiddler API token", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (e *fiddlerExporter) Shutdown(context.Context) error {
+	if e.telemetry != nil {
+		e.telemetry.Shutdown()
+	}
+	return nil
+}
+
+func (e *fiddlerExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	set := e.config.Logs.translatorSettings()
+	events, dropped := translator.LogsToEvents(ld, set)
+	if dropped > 0 {
+		e.logger.Warn("Dropped log records that could not be converted to Fiddler events",
+			zap.Int("dropped", dropped))
+	}
+	retryModels, err := e.publish(ctx, e.config.Logs.EnvType, events)
+	if len(retryModels) > 0 {
+		return consumererror.NewLogs(err, translator.FilterLogs(ld, set, retryModels))
+	}
+	return err
+}
+
+func (e *fiddlerExporter) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
+	set := e.config.Metrics.translatorSettings()
+	events, dropped := translator.MetricsToEvents(md, set)
+	if dropped > 0 {
+		e.logger.Warn("Dropped data points that could not be converted to Fiddler events",
+			zap.Int("dropped", dropped))
+	}
+	retryModels, err := e.publish(ctx, e.config.Metrics.EnvType, events)
+	if len(retryModels) > 0 {
+		return consumererror.NewMetrics(err, translator.FilterMetrics(md, set, retryModels))
+	}
+	return err
+}
+
+func (e *fiddlerExporter) pushTraces(ctx context.Context, td ptrace.Traces) error {
+	set := e.config.Traces.translatorSettings()
+	events, dropped := translator.SpansToEvents(td, set)
+	if dropped > 0 {
+		e.logger.Warn("Dropped GenAI spans that could not be converted to Fiddler events",
+			zap.Int("dropped", dropped))
+	}
+	retryModels, err := e.publish(ctx, e.config.Traces.EnvType, events)
+	if len(retryModels) > 0 {
+		return consumererror.NewTraces(err, translator.FilterSpans(td, set, retryModels))
+	}
+	return err
+}
+
+// publish publishes the events of each model, and returns the models whose
+// events can be retried. When some models can be retried, only their errors
+// are returned, so that exporterhelper retries just their data: the events
+// of models that failed permanently are dropped and logged here, and the
+// events of models that succeeded are not sent again.
+func (e *fiddlerExporter) publish(ctx context.Context, envType string, events map[string][]fiddler.Event) (map[string]struct{}, error) {
+	retryModels := make(map[string]struct{})
+	var retryErrs, permanentErrs error
+	for _, modelID := range slices.Sorted(maps.Keys(events)) {
+		err := e.client.PublishEvents(ctx, modelID, envType, events[modelID])
+		if err == nil {
+			continue
+		}
+		err = exportError(fmt.Errorf("failed to publish events to model %q: %w", modelID, err))
+		if consumererror.IsPermanent(err) {
+			permanentErrs = errors.Join(permanentErrs, err)
+			continue
+		}
+		retryModels[modelID] = struct{}{}
+		retryErrs = errors.Join(retryErrs, err)
+	}
+	if len(retryModels) == 0 {
+		return nil, permanentErrs
+	}
+	if permanentErrs != nil {
+		e.logger.Error("Dropped events rejected by Fiddler", zap.Error(permanentErrs))
+	}
+	return retryModels, retryErrs
+}
+
+// exportError classifies an error returned by the Fiddler client for the
+// exporterhelper retry mechanism. Requests that Fiddler rejected are not
+// retried, and throttled requests are retried once the delay requested by
+// Fiddler, the rate limit or the circuit breaker elapsed.
+func exportError(err error) error {
+	var authErr *fiddler.AuthError
+	var queryErr *fiddler.QueryError
+	var rateLimitErr *fiddler.RateLimitError
+	var circuitErr *fiddler.CircuitOpenError
+	var pausedErr *fiddler.PausedError
+	switch {
+	case errors.Is(err, fiddler.ErrInvalidRequest), errors.As(err, &authErr), errors.As(err, &queryErr):
+		return consumererror.NewPermanent(err)
+	case errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0:
+		return exporterhelper.NewThrottleRetry(err, rateLimitErr.RetryAfter)
+	case errors.As(err, &circuitErr):
+		return exporterhelper.NewThrottleRetry(err, circuitErr.RetryAfter)
+	case errors.As(err, &pausedErr):
+		return exporterhelper.NewThrottleRetry(err, pausedErr.RetryAfter)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,719 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensionauth"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler/fiddlertest"
+)
+
+func TestExportLogs(t *testing.T) {
+	newLogs := func() plog.Logs {
+		ld := plog.NewLogs()
+		lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		lr.Attributes().PutStr("fiddler.model.id", "model-a")
+		lr.Attributes().PutInt("age", 42)
+		return ld
+	}
+
+	tests := []struct {
+		name           string
+		logs           plog.Logs
+		responseStatus int
+		wantRequest    bool
+		wantErr        bool
+		wantPermanent  bool
+	}{
+		{
+			name:           "publish events",
+			logs:           newLogs(),
+			responseStatus: http.StatusOK,
+			wantRequest:    true,
+		},
+		{
+			name:           "no events",
+			logs:           plog.NewLogs(),
+			responseStatus: http.StatusOK,
+		},
+		{
+			name:           "retryable error",
+			logs:           newLogs(),
+			responseStatus: http.StatusServiceUnavailable,
+			wantRequest:    true,
+			wantErr:        true,
+		},
+		{
+			name:           "permanent error",
+			logs:           newLogs(),
+			responseStatus: http.StatusBadRequest,
+			wantRequest:    true,
+			wantErr:        true,
+			wantPermanent:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = true
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v3/events", r.URL.Path)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+				gotBody, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"model_id":"model-a","env_type":"PRODUCTION","source":{"type":"EVENTS","events":[{"age":42}]}}`, string(gotBody))
+
+				w.WriteHeader(tt.responseStatus)
+			}))
+			defer server.Close()
+
+			cfg := &Config{
+				ClientConfig: confighttp.ClientConfig{Endpoint: server.URL},
+				Token:        "test-token",
+				Logs: LogsConfig{
+					PublishConfig: PublishConfig{
+						ModelIDAttribute: "fiddler.model.id",
+						EnvType:          envTypeProduction,
+					},
+				},
+			}
+			exp := newExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+			require.NoError(t, exp.Start(t.Context(), componenttest.NewNopHost()))
+
+			err := exp.pushLogs(t.Context(), tt.logs)
+			assert.Equal(t, tt.wantRequest, requested)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+		})
+	}
+}
+
+func TestExportMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/events", r.URL.Path)
+		gotBody, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"model_id":"model-a","env_type":"PRE_PRODUCTION","source":{"type":"EVENTS","events":[{"loan.amount":1250.5}]}}`, string(gotBody))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		ClientConfig: confighttp.ClientConfig{Endpoint: server.URL},
+		Token:        "test-token",
+		Metrics: MetricsConfig{
+			PublishConfig: PublishConfig{
+				ModelID: "model-a",
+				EnvType: envTypePreProduction,
+			},
+		},
+	}
+	exp := newExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	require.NoError(t, exp.Start(t.Context(), componenttest.NewNopHost()))
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("loan.amount")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1250.5)
+
+	assert.NoError(t, exp.pushMetrics(t.Context(), md))
+}
+
+func TestExportTraces(t *testing.T) {
+	server := fiddlertest.NewServer()
+	defer server.Close()
+
+	cfg := &Config{
+		ClientConfig: confighttp.ClientConfig{Endpoint: server.URL},
+		Token:        "test-token",
+		Traces: TracesConfig{
+			PublishConfig: PublishConfig{
+				ModelID: "llm-app",
+				EnvType: envTypeProduction,
+			},
+		},
+	}
+	exp := newExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	require.NoError(t, exp.Start(t.Context(), componenttest.NewNopHost()))
+
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().Attributes().PutStr("http.request.method", "POST")
+	span := spans.AppendEmpty()
+	span.Attributes().PutStr("gen_ai.operation.name", "chat")
+	span.Attributes().PutInt("gen_ai.usage.output_tokens", 30)
+
+	require.NoError(t, exp.pushTraces(t.Context(), td))
+	assert.Equal(t, []fiddler.Event{{
+		"gen_ai.operation.name":            "chat",
+		"gen_ai.usage.output_tokens":       30.0,
+		"gen_ai.client.operation.duration": 0.0,
+	}}, server.Events("llm-app"))
+}
+
+func TestExportWithCompression(t *testing.T) {
+	server := fiddlertest.NewServer(fiddlertest.WithToken("test-token"))
+	defer server.Close()
+
+	exp := startTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = server.URL
+		cfg.ClientConfig.Compression = configcompression.TypeGzip
+	})
+	require.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+
+	batches := server.Batches()
+	require.Len(t, batches, 1)
+	assert.Equal(t, "gzip", batches[0].Header.Get("Content-Encoding"))
+	assert.Equal(t, []fiddler.Event{{"age": 42.0}}, batches[0].Events)
+}
+
+func TestExportWithCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := writeCAFile(t, server)
+
+	tests := []struct {
+		name    string
+		tls     configtls.ClientConfig
+		wantErr string
+	}{
+		{
+			name: "trusted ca",
+			tls:  configtls.ClientConfig{Config: configtls.Config{CAFile: caFile}},
+		},
+		{
+			name:    "unknown ca",
+			wantErr: "certificate signed by unknown authority",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := startTestExporter(t, func(cfg *Config) {
+				cfg.ClientConfig.Endpoint = server.URL
+				cfg.ClientConfig.TLS = tt.tls
+			})
+
+			err := exp.pushLogs(t.Context(), newTestLogs())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExportWithClientCertificate(t *testing.T) {
+	certFile, keyFile, clientCert := writeClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "fiddler-collector", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writeCAFile(t, server)
+
+	tests := []struct {
+		name    string
+		tls     configtls.Config
+		wantErr bool
+	}{
+		{
+			name: "client certificate",
+			tls:  configtls.Config{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+		},
+		{
+			name:    "no client certificate",
+			tls:     configtls.Config{CAFile: caFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := startTestExporter(t, func(cfg *Config) {
+				cfg.ClientConfig.Endpoint = server.URL
+				cfg.ClientConfig.TLS = configtls.ClientConfig{Config: tt.tls}
+			})
+
+			err := exp.pushLogs(t.Context(), newTestLogs())
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExportWithHostOverride(t *testing.T) {
+	// The test certificate is valid for example.com, while the server is
+	// only reachable through its IP address.
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "example.com", r.Host)
+		assert.Equal(t, "example.com", r.TLS.ServerName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := writeCAFile(t, server)
+	exp := startTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = server.URL
+		cfg.ClientConfig.Headers = map[string]configopaque.String{"Host": "example.com"}
+		cfg.ClientConfig.TLS = configtls.ClientConfig{
+			Config:     configtls.Config{CAFile: caFile},
+			ServerName: "example.com",
+		}
+	})
+	require.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+}
+
+func TestExportThroughProxy(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.Host
+		assert.Equal(t, "/v3/events", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	exp := startTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = "http://fiddler.example.com"
+		cfg.ClientConfig.ProxyURL = proxy.URL
+	})
+	require.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+	assert.Equal(t, "fiddler.example.com", proxiedHost)
+}
+
+func TestExportWithCustomHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "risk-team", r.Header.Get("X-Tenant-ID"))
+		assert.Equal(t, "acme-collector/1.0", r.Header.Get("User-Agent"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exp := startTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = server.URL
+		cfg.ClientConfig.Headers = map[string]configopaque.String{
+			"X-Tenant-ID": "risk-team",
+			"User-Agent":  "acme-collector/1.0",
+		}
+	})
+	require.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+}
+
+func TestExportWithMaxConnsPerHost(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exp := startTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = server.URL
+		cfg.ClientConfig.MaxConnsPerHost = 2
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestExportWithAuthExtension(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer oauth2-access-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	authID := component.MustNewID("oauth2client")
+	exp := newTestExporter(t, func(cfg *Config) {
+		cfg.ClientConfig.Endpoint = server.URL
+		cfg.ClientConfig.Auth = configoptional.Some(configauth.Config{AuthenticatorID: authID})
+		cfg.Token = ""
+	})
+
+	host := &mockHost{extensions: map[component.ID]component.Component{
+		authID: &mockAuthClient{ClientRoundTripperFunc: func(base http.RoundTripper) (http.RoundTripper, error) {
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				r = r.Clone(r.Context())
+				r.Header.Set("Authorization", "Bearer oauth2-access-token")
+				return base.RoundTrip(r)
+			}), nil
+		}},
+	}}
+	require.NoError(t, exp.Start(t.Context(), host))
+	require.NoError(t, exp.pushLogs(t.Context(), newTestLogs()))
+}
+
+func TestExportPartialFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      map[string]int
+		wantPermanent bool
+		wantRetried   []string
+	}{
+		{
+			name:        "rejected and unavailable models",
+			statuses:    map[string]int{"model-a": http.StatusBadRequest, "model-b": http.StatusBadGateway},
+			wantRetried: []string{"model-b"},
+		},
+		{
+			name:        "published and unavailable models",
+			statuses:    map[string]int{"model-a": http.StatusOK, "model-b": http.StatusBadGateway},
+			wantRetried: []string{"model-b"},
+		},
+		{
+			name:          "rejected models",
+			statuses:      map[string]int{"model-a": http.StatusBadRequest, "model-b": http.StatusUnauthorized},
+			wantPermanent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					ModelID string `json:"model_id"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				w.WriteHeader(tt.statuses[req.ModelID])
+			}))
+			defer server.Close()
+
+			exp := startTestExporter(t, func(cfg *Config) {
+				cfg.ClientConfig.Endpoint = server.URL
+				cfg.Logs.ModelIDAttribute = "fiddler.model.id"
+			})
+
+			ld := plog.NewLogs()
+			lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+			for _, modelID := range []string{"model-a", "model-b"} {
+				lr := lrs.AppendEmpty()
+				lr.Attributes().PutStr("fiddler.model.id", modelID)
+				lr.Attributes().PutInt("age", 42)
+			}
+
+			err := exp.pushLogs(t.Context(), ld)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+
+			var logsErr consumererror.Logs
+			if len(tt.wantRetried) == 0 {
+				assert.False(t, errors.As(err, &logsErr))
+				return
+			}
+			require.ErrorAs(t, err, &logsErr)
+			var retried []string
+			retriedRecords := logsErr.Data().ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			for i := 0; i < retriedRecords.Len(); i++ {
+				modelID, _ := retriedRecords.At(i).Attributes().Get("fiddler.model.id")
+				retried = append(retried, modelID.Str())
+			}
+			assert.Equal(t, tt.wantRetried, retried)
+		})
+	}
+}
+
+func TestExportError(t *testing.T) {
+	apiErr := func(status int) *fiddler.APIError {
+		return &fiddler.APIError{URL: "https://app.fiddler.ai/v3/events", StatusCode: status}
+	}
+	tests := []struct {
+		name          string
+		err           error
+		wantPermanent bool
+		wantThrottle  string
+	}{
+		{
+			name:          "invalid request",
+			err:           fmt.Errorf("%w: unsupported value", fiddler.ErrInvalidRequest),
+			wantPermanent: true,
+		},
+		{
+			name:          "unauthorized",
+			err:           &fiddler.AuthError{APIError: apiErr(http.StatusUnauthorized)},
+			wantPermanent: true,
+		},
+		{
+			name:          "rejected request",
+			err:           &fiddler.QueryError{APIError: apiErr(http.StatusBadRequest)},
+			wantPermanent: true,
+		},
+		{
+			name:         "throttled",
+			err:          &fiddler.RateLimitError{APIError: apiErr(http.StatusTooManyRequests), RetryAfter: 10 * time.Second},
+			wantThrottle: "Throttle (10s)",
+		},
+		{
+			name: "throttled without delay",
+			err:  &fiddler.RateLimitError{APIError: apiErr(http.StatusServiceUnavailable)},
+		},
+		{
+			name:         "circuit open",
+			err:          &fiddler.CircuitOpenError{URL: "https://app.fiddler.ai/v3/events", RetryAfter: time.Minute},
+			wantThrottle: "Throttle (1m0s)",
+		},
+		{
+			name:         "paused by rate limit",
+			err:          &fiddler.PausedError{URL: "https://app.fiddler.ai/v3/events", RetryAfter: 45 * time.Second},
+			wantThrottle: "Throttle (45s)",
+		},
+		{
+			name: "server error",
+			err:  apiErr(http.StatusBadGateway),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exportError(tt.err)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+			if tt.wantThrottle != "" {
+				assert.ErrorContains(t, err, tt.wantThrottle)
+			} else {
+				assert.NotContains(t, err.Error(), "Throttle")
+			}
+		})
+	}
+}
+
+func TestStartValidatesToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		validateToken bool
+		wantRequest   bool
+		wantErr       bool
+	}{
+		{
+			name:          "valid token",
+			status:        http.StatusOK,
+			validateToken: true,
+			wantRequest:   true,
+		},
+		{
+			name:          "invalid token",
+			status:        http.StatusUnauthorized,
+			validateToken: true,
+			wantRequest:   true,
+			wantErr:       true,
+		},
+		{
+			name:          "missing permissions",
+			status:        http.StatusForbidden,
+			validateToken: true,
+			wantRequest:   true,
+			wantErr:       true,
+		},
+		{
+			name:          "unavailable",
+			status:        http.StatusServiceUnavailable,
+			validateToken: true,
+			wantRequest:   true,
+		},
+		{
+			name:   "disabled",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = true
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/v3/server-info", r.URL.Path)
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			cfg := &Config{
+				ClientConfig:  confighttp.ClientConfig{Endpoint: server.URL},
+				Token:         "test-token",
+				ValidateToken: tt.validateToken,
+			}
+			exp := newExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+			err := exp.Start(t.Context(), componenttest.NewNopHost())
+			assert.Equal(t, tt.wantRequest, requested)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, fiddler.ErrUnauthorized)
+		})
+	}
+}
+
+// newTestExporter creates an exporter publishing logs to model-a with the
+// test token, after mutate has set the case-specific configuration.
+func newTestExporter(t *testing.T, mutate func(*Config)) *fiddlerExporter {
+	cfg := &Config{
+		Token: "test-token",
+		Logs: LogsConfig{
+			PublishConfig: PublishConfig{
+				ModelID: "model-a",
+				EnvType: envTypeProduction,
+			},
+		},
+	}
+	mutate(cfg)
+	require.NoError(t, cfg.Validate())
+	return newExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+}
+
+// startTestExporter is newTestExporter for exporters that need no extensions.
+func startTestExporter(t *testing.T, mutate func(*Config)) *fiddlerExporter {
+	exp := newTestExporter(t, mutate)
+	require.NoError(t, exp.Start(t.Context(), componenttest.NewNopHost()))
+	return exp
+}
+
+// newTestLogs returns a log record that is published as {"age": 42}.
+func newTestLogs() plog.Logs {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutInt("age", 42)
+	return ld
+}
+
+// writeCAFile writes the certificate of server to a file, for use as the CA
+// that clients trust.
+func writeCAFile(t *testing.T, server *httptest.Server) string {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+	return caFile
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key
+// to files, and returns their paths along with the certificate.
+func writeClientCertificate(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fiddler-collector"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+type mockHost struct {
+	extensions map[component.ID]component.Component
+}
+
+func (h *mockHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+var (
+	_ extension.Extension      = (*mockAuthClient)(nil)
+	_ extensionauth.HTTPClient = (*mockAuthClient)(nil)
+)
+
+type mockAuthClient struct {
+	component.StartFunc
+	component.ShutdownFunc
+	extensionauth.ClientRoundTripperFunc
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+)
+
+// exporters holds the exporter of each component ID, so that the logs,
+// metrics and traces exporters created for the same component share it.
+var exporters = sharedcomponent.NewSharedComponents()
+
+const (
+	defaultModelIDAttribute    = "fiddler.model.id"
+	defaultMaxIdleConnsPerHost = 10
+)
+
+// NewFactory creates a factory for Fiddler exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
+		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Timeout = 30 * time.Second
+	// All requests go to a single Fiddler host, so keep enough idle
+	// connections around for every sending queue consumer to reuse one
+	// instead of the net/http default of two.
+	clientConfig.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+
+	return &Config{
+		ClientConfig:  clientConfig,
+		RetryConfig:   configretry.NewDefaultBackOffConfig(),
+		QueueConfig:   exporterhelper.NewDefaultQueueConfig(),
+		ValidateToken: true,
+		Logs: LogsConfig{
+			PublishConfig: PublishConfig{
+				ModelIDAttribute: defaultModelIDAttribute,
+				EnvType:          envTypeProduction,
+			},
+		},
+		Metrics: MetricsConfig{
+			PublishConfig: PublishConfig{
+				ModelIDAttribute: defaultModelIDAttribute,
+				EnvType:          envTypeProduction,
+			},
+		},
+		Traces: TracesConfig{
+			PublishConfig: PublishConfig{
+				ModelIDAttribute: defaultModelIDAttribute,
+				EnvType:          envTypeProduction,
+			},
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: 5,
+			Cooldown:         time.Minute,
+		},
+	}
+}
+
+func createLogsExporter(
+	ctx context.Context,
+	set exporter.Settings,
+	cfg component.Config,
+) (exporter.Logs, error) {
+	oCfg := cfg.(*Config)
+
+	exp := getOrCreateExporter(oCfg, set)
+	return exporterhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		exp.Unwrap().(*fiddlerExporter).pushLogs,
+		exporterhelper.WithStart(exp.Start),
+		exporterhelper.WithShutdown(exp.Shutdown),
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// explicitly disable since we rely on http.Client timeout logic.
+		exporterhelper.WithTimeout(exporterhelper.TimeoutConfig{Timeout: 0}),
+		exporterhelper.WithRetry(oCfg.RetryConfig),
+		exporterhelper.WithQueue(oCfg.QueueConfig),
+	)
+}
+
+func createMetricsExporter(
+	ctx context.Context,
+	set exporter.Settings,
+	cfg component.Config,
+) (exporter.Metrics, error) {
+	oCfg := cfg.(*Config)
+
+	exp := getOrCreateExporter(oCfg, set)
+	return exporterhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		exp.Unwrap().(*fiddlerExporter).pushMetrics,
+		exporterhelper.WithStart(exp.Start),
+		exporterhelper.WithShutdown(exp.Shutdown),
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// explicitly disable since we rely on http.Client timeout logic.
+		exporterhelper.WithTimeout(exporterhelper.TimeoutConfig{Timeout: 0}),
+		exporterhelper.WithRetry(oCfg.RetryConfig),
+		exporterhelper.WithQueue(oCfg.QueueConfig),
+	)
+}
+
+func createTracesExporter(
+	ctx context.Context,
+	set exporter.Settings,
+	cfg component.Config,
+) (exporter.Traces, error) {
+	oCfg := cfg.(*Config)
+
+	exp := getOrCreateExporter(oCfg, set)
+	return exporterhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		exp.Unwrap().(*fiddlerExporter).pushTraces,
+		exporterhelper.WithStart(exp.Start),
+		exporterhelper.WithShutdown(exp.Shutdown),
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// explicitly disable since we rely on http.Client timeout logic.
+		exporterhelper.WithTimeout(exporterhelper.TimeoutConfig{Timeout: 0}),
+		exporterhelper.WithRetry(oCfg.RetryConfig),
+		exporterhelper.WithQueue(oCfg.QueueConfig),
+	)
+}
+
+// getOrCreateExporter returns the exporter shared by all signals of the
+// component with the ID in set, creating it on first use. Sharing it means the
+// logs, metrics and traces pipelines use a single HTTP client, circuit breaker
+// and rate limiter towards Fiddler.
+func getOrCreateExporter(cfg *Config, set exporter.Settings) *sharedcomponent.SharedComponent {
+	return exporters.GetOrAdd(set.ID, func() component.Component {
+		return newExporter(cfg, set)
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler/fiddlertest"
+)
+
+func TestSignalsShareClient(t *testing.T) {
+	server := fiddlertest.NewServer()
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = server.URL
+	cfg.Token = "test-token"
+	cfg.RetryConfig.Enabled = false
+	cfg.QueueConfig.Enabled = false
+	cfg.Logs.ModelID = "model-a"
+	cfg.Metrics.ModelID = "model-a"
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.FailureThreshold = 1
+
+	factory := NewFactory()
+	set := exportertest.NewNopSettings(metadata.Type)
+	logsExporter, err := factory.CreateLogs(t.Context(), set, cfg)
+	require.NoError(t, err)
+	metricsExporter, err := factory.CreateMetrics(t.Context(), set, cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, logsExporter.Start(t.Context(), componenttest.NewNopHost()))
+	require.NoError(t, metricsExporter.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, logsExporter.Shutdown(t.Context()))
+		assert.NoError(t, metricsExporter.Shutdown(t.Context()))
+	}()
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("loan.amount")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1250.5)
+
+	// The failed logs request opens the circuit breaker, which the metrics
+	// exporter sees because both signals use the same client.
+	server.FailNext(1, http.StatusBadGateway, nil)
+	require.Error(t, logsExporter.ConsumeLogs(t.Context(), newTestLogs()))
+	assert.ErrorIs(t, metricsExporter.ConsumeMetrics(t.Context(), md), fiddler.ErrCircuitOpen)
+	assert.Empty(t, server.Batches())
+}
+
+func TestSharedClientIsRecreatedAfterShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	set := exportertest.NewNopSettings(metadata.Type)
+
+	exp := getOrCreateExporter(cfg, set)
+	assert.Same(t, exp, getOrCreateExporter(cfg, set))
+
+	require.NoError(t, exp.Shutdown(t.Context()))
+	recreated := getOrCreateExporter(cfg, set)
+	assert.NotSame(t, exp, recreated)
+	require.NoError(t, recreated.Shutdown(t.Context()))
+}
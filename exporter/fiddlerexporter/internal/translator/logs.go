@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+// LogsToEvents groups the log records in ld by target model and converts each
+// of them to a Fiddler event. Columns are taken from the record's map body
+// and attributes, with attributes taking precedence. Records for which no
+// model can be resolved, that carry no columns or that carry a NaN or infinite
+// number are counted as dropped.
+func LogsToEvents(ld plog.Logs, set Settings) (map[string][]fiddler.Event, int) {
+	events := make(map[string][]fiddler.Event)
+	dropped := 0
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resourceModelID := set.resourceModelID(rl.Resource().Attributes())
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+
+				modelID := set.modelID(resourceModelID, lr.Attributes())
+				if modelID == "" {
+					dropped++
+					continue
+				}
+
+				event := logRecordToEvent(lr, set)
+				if len(event) == 0 {
+					dropped++
+					continue
+				}
+				events[modelID] = append(events[modelID], event)
+			}
+		}
+	}
+	return events, dropped
+}
+
+// FilterLogs returns a copy of ld holding only the log records that
+// LogsToEvents publishes to one of modelIDs, e.g. to retry the records of the
+// models that failed.
+func FilterLogs(ld plog.Logs, set Settings, modelIDs map[string]struct{}) plog.Logs {
+	filtered := plog.NewLogs()
+	ld.CopyTo(filtered)
+	filtered.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		resourceModelID := set.resourceModelID(rl.Resource().Attributes())
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				_, ok := modelIDs[set.modelID(resourceModelID, lr.Attributes())]
+				return !ok
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	return filtered
+}
+
+// logRecordToEvent converts lr to an event, or returns nil when lr has no
+// columns or a column that cannot be published.
+func logRecordToEvent(lr plog.LogRecord, set Settings) fiddler.Event {
+	event := make(fiddler.Event)
+	if lr.Body().Type() == pcommon.ValueTypeMap {
+		for k, v := range lr.Body().Map().All() {
+			if !isFinite(v) {
+				return nil
+			}
+			event[k] = v.AsRaw()
+		}
+	}
+	for k, v := range lr.Attributes().All() {
+		if k == set.ModelIDAttribute {
+			continue
+		}
+		if !isFinite(v) {
+			return nil
+		}
+		event[k] = v.AsRaw()
+	}
+	if len(event) == 0 {
+		return nil
+	}
+
+	if set.TimestampColumn != "" {
+		timestamp := lr.Timestamp()
+		if timestamp == 0 {
+			timestamp = lr.ObservedTimestamp()
+		}
+		event[set.TimestampColumn] = timestamp.AsTime().Format(time.RFC3339Nano)
+	}
+	return event
+}
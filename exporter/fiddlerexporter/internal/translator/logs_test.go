@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+func TestLogsToEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		logs        func() plog.Logs
+		settings    Settings
+		wantEvents  map[string][]fiddler.Event
+		wantDropped int
+	}{
+		{
+			name: "map body and attributes",
+			logs: func() plog.Logs {
+				ld := plog.NewLogs()
+				lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+				lr.SetTimestamp(pcommon.Timestamp(1719158400000000000)) // 2024-06-23T16:00:00Z
+				body := lr.Body().SetEmptyMap()
+				body.PutInt("age", 42)
+				body.PutDouble("score", 0.75)
+				lr.Attributes().PutStr("fiddler.model.id", "model-a")
+				lr.Attributes().PutStr("region", "us-east")
+				return ld
+			},
+			settings: Settings{
+				ModelIDAttribute: "fiddler.model.id",
+				TimestampColumn:  "timestamp",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-a": {{
+					"age":       int64(42),
+					"score":     0.75,
+					"region":    "us-east",
+					"timestamp": "2024-06-23T16:00:00Z",
+				}},
+			},
+		},
+		{
+			name: "model resolved from resource and default",
+			logs: func() plog.Logs {
+				ld := plog.NewLogs()
+				rl := ld.ResourceLogs().AppendEmpty()
+				rl.Resource().Attributes().PutStr("fiddler.model.id", "model-b")
+				rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutStr("prediction", "yes")
+				ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutStr("prediction", "no")
+				return ld
+			},
+			settings: Settings{
+				ModelID:          "default-model",
+				ModelIDAttribute: "fiddler.model.id",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-b":       {{"prediction": "yes"}},
+				"default-model": {{"prediction": "no"}},
+			},
+		},
+		{
+			name: "records without model or columns are dropped",
+			logs: func() plog.Logs {
+				ld := plog.NewLogs()
+				lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+				lrs.AppendEmpty().Attributes().PutStr("prediction", "yes")
+				lr := lrs.AppendEmpty()
+				lr.Body().SetStr("plain text")
+				lr.Attributes().PutStr("fiddler.model.id", "model-a")
+				return ld
+			},
+			settings: Settings{
+				ModelIDAttribute: "fiddler.model.id",
+			},
+			wantEvents:  map[string][]fiddler.Event{},
+			wantDropped: 2,
+		},
+		{
+			name: "records with non-finite numbers are dropped",
+			logs: func() plog.Logs {
+				ld := plog.NewLogs()
+				lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+				lrs.AppendEmpty().Attributes().PutDouble("score", math.NaN())
+				lrs.AppendEmpty().Body().SetEmptyMap().PutDouble("score", math.Inf(1))
+				lrs.AppendEmpty().Attributes().PutEmptySlice("scores").AppendEmpty().SetDouble(math.Inf(-1))
+				lrs.AppendEmpty().Attributes().PutDouble("score", 0.5)
+				return ld
+			},
+			settings: Settings{
+				ModelID: "model-a",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-a": {{"score": 0.5}},
+			},
+			wantDropped: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, dropped := LogsToEvents(tt.logs(), tt.settings)
+			assert.Equal(t, tt.wantEvents, events)
+			assert.Equal(t, tt.wantDropped, dropped)
+		})
+	}
+}
+
+func TestFilterLogs(t *testing.T) {
+	set := Settings{ModelID: "model-a", ModelIDAttribute: "fiddler.model.id"}
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("fiddler.model.id", "model-b")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Attributes().PutInt("age", 42)
+	lr := lrs.AppendEmpty()
+	lr.Attributes().PutStr("fiddler.model.id", "model-c")
+	lr.Attributes().PutInt("age", 7)
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutInt("age", 30)
+
+	filtered := FilterLogs(ld, set, map[string]struct{}{"model-b": {}})
+	assert.Equal(t, 1, filtered.LogRecordCount())
+	events, dropped := LogsToEvents(filtered, set)
+	assert.Equal(t, map[string][]fiddler.Event{"model-b": {{"age": int64(42)}}}, events)
+	assert.Zero(t, dropped)
+	assert.Equal(t, 3, ld.LogRecordCount())
+}
@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+type rowKey struct {
+	modelID   string
+	timestamp pcommon.Timestamp
+	columns   string
+}
+
+// metricsBuilder merges data points into Fiddler events. Data points of
+// different metrics sharing a model, timestamp and attribute set end up in
+// the same event, with one column per metric.
+type metricsBuilder struct {
+	set     MetricsSettings
+	include map[string]struct{}
+	rows    map[rowKey]fiddler.Event
+	order   []rowKey
+	dropped int
+}
+
+func newMetricsBuilder(set MetricsSettings) *metricsBuilder {
+	return &metricsBuilder{
+		set:     set,
+		include: toSet(set.Include),
+		rows:    make(map[rowKey]fiddler.Event),
+	}
+}
+
+// MetricsToEvents groups the data points in md by target model and converts
+// them to Fiddler events. Gauges and sums are published with their value, so
+// cumulative sums are published as running totals, and histograms with their
+// mean. Data points of other types, with non-finite values, for which no model
+// can be resolved or whose metric name is also the name of one of their
+// attributes or of the timestamp column are counted as dropped.
+func MetricsToEvents(md pmetric.Metrics, set MetricsSettings) (map[string][]fiddler.Event, int) {
+	b := newMetricsBuilder(set)
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		resourceModelID := set.resourceModelID(rm.Resource().Attributes())
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				b.addMetric(resourceModelID, sm.Metrics().At(k))
+			}
+		}
+	}
+
+	events := make(map[string][]fiddler.Event)
+	for _, key := range b.order {
+		events[key.modelID] = append(events[key.modelID], b.rows[key])
+	}
+	return events, b.dropped
+}
+
+// FilterMetrics returns a copy of md holding only the data points that
+// MetricsToEvents publishes to one of modelIDs, e.g. to retry the data points
+// of the models that failed.
+func FilterMetrics(md pmetric.Metrics, set MetricsSettings, modelIDs map[string]struct{}) pmetric.Metrics {
+	include := toSet(set.Include)
+	filtered := pmetric.NewMetrics()
+	md.CopyTo(filtered)
+	filtered.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		resourceModelID := set.resourceModelID(rm.Resource().Attributes())
+		keep := func(attrs pcommon.Map) bool {
+			_, ok := modelIDs[set.modelID(resourceModelID, attrs)]
+			return ok
+		}
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				if _, ok := include[m.Name()]; len(include) > 0 && !ok {
+					return true
+				}
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					m.Gauge().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool { return !keep(dp.Attributes()) })
+					return m.Gauge().DataPoints().Len() == 0
+				case pmetric.MetricTypeSum:
+					m.Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool { return !keep(dp.Attributes()) })
+					return m.Sum().DataPoints().Len() == 0
+				case pmetric.MetricTypeHistogram:
+					m.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool { return !keep(dp.Attributes()) })
+					return m.Histogram().DataPoints().Len() == 0
+				default:
+					// Other types are never published.
+					return true
+				}
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+	return filtered
+}
+
+func toSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
+
+func (b *metricsBuilder) addMetric(resourceModelID string, m pmetric.Metric) {
+	if len(b.include) > 0 {
+		if _, ok := b.include[m.Name()]; !ok {
+			return
+		}
+	}
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		b.addNumberDataPoints(resourceModelID, m.Name(), m.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		b.addNumberDataPoints(resourceModelID, m.Name(), m.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if !dp.HasSum() || dp.Count() == 0 {
+				b.dropped++
+				continue
+			}
+			b.addValue(resourceModelID, m.Name(), dp.Attributes(), dp.Timestamp(), dp.Sum()/float64(dp.Count()))
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		b.dropped += m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		b.dropped += m.Summary().DataPoints().Len()
+	}
+}
+
+func (b *metricsBuilder) addNumberDataPoints(resourceModelID, name string, dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			b.addValue(resourceModelID, name, dp.Attributes(), dp.Timestamp(), dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			b.addValue(resourceModelID, name, dp.Attributes(), dp.Timestamp(), dp.DoubleValue())
+		default:
+			b.dropped++
+		}
+	}
+}
+
+func (b *metricsBuilder) addValue(resourceModelID, name string, attrs pcommon.Map, timestamp pcommon.Timestamp, value any) {
+	if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		// Non-finite values cannot be encoded as JSON.
+		b.dropped++
+		return
+	}
+
+	modelID := b.set.modelID(resourceModelID, attrs)
+	if modelID == "" {
+		b.dropped++
+		return
+	}
+
+	columns := make(map[string]any, attrs.Len())
+	for k, v := range attrs.All() {
+		if k == b.set.ModelIDAttribute {
+			continue
+		}
+		columns[k] = v.AsRaw()
+	}
+	if _, ok := columns[name]; ok || name == b.set.TimestampColumn {
+		// The value would overwrite an attribute or the timestamp.
+		b.dropped++
+		return
+	}
+	// encoding/json sorts map keys, making the encoded columns usable as a key.
+	encoded, err := json.Marshal(columns)
+	if err != nil {
+		b.dropped++
+		return
+	}
+
+	key := rowKey{modelID: modelID, timestamp: timestamp, columns: string(encoded)}
+	row, ok := b.rows[key]
+	if !ok {
+		row = fiddler.Event(columns)
+		if b.set.TimestampColumn != "" {
+			row[b.set.TimestampColumn] = timestamp.AsTime().Format(time.RFC3339Nano)
+		}
+		b.rows[key] = row
+		b.order = append(b.order, key)
+	}
+	row[name] = value
+}
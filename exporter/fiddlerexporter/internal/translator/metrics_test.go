@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+func TestMetricsToEvents(t *testing.T) {
+	const ts = pcommon.Timestamp(1719158400000000000) // 2024-06-23T16:00:00Z
+
+	tests := []struct {
+		name        string
+		metrics     func() pmetric.Metrics
+		settings    MetricsSettings
+		wantEvents  map[string][]fiddler.Event
+		wantDropped int
+	}{
+		{
+			name: "points sharing timestamp and attributes are merged",
+			metrics: func() pmetric.Metrics {
+				md := pmetric.NewMetrics()
+				rm := md.ResourceMetrics().AppendEmpty()
+				rm.Resource().Attributes().PutStr("fiddler.model.id", "model-a")
+				ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+				gauge := ms.AppendEmpty()
+				gauge.SetName("loan.amount")
+				dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetTimestamp(ts)
+				dp.SetDoubleValue(1250.5)
+				dp.Attributes().PutStr("region", "us-east")
+
+				sum := ms.AppendEmpty()
+				sum.SetName("requests")
+				dp = sum.SetEmptySum().DataPoints().AppendEmpty()
+				dp.SetTimestamp(ts)
+				dp.SetIntValue(12)
+				dp.Attributes().PutStr("region", "us-east")
+
+				histogram := ms.AppendEmpty()
+				histogram.SetName("latency")
+				hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+				hdp.SetTimestamp(ts)
+				hdp.SetCount(4)
+				hdp.SetSum(2)
+				hdp.Attributes().PutStr("region", "eu-west")
+				return md
+			},
+			settings: MetricsSettings{
+				Settings: Settings{
+					ModelIDAttribute: "fiddler.model.id",
+					TimestampColumn:  "timestamp",
+				},
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-a": {
+					{"region": "us-east", "loan.amount": 1250.5, "requests": int64(12), "timestamp": "2024-06-23T16:00:00Z"},
+					{"region": "eu-west", "latency": 0.5, "timestamp": "2024-06-23T16:00:00Z"},
+				},
+			},
+		},
+		{
+			name: "include filter and default model",
+			metrics: func() pmetric.Metrics {
+				md := pmetric.NewMetrics()
+				ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+				for _, name := range []string{"loan.amount", "ignored"} {
+					m := ms.AppendEmpty()
+					m.SetName(name)
+					dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+					dp.SetTimestamp(ts)
+					dp.SetIntValue(1)
+				}
+				return md
+			},
+			settings: MetricsSettings{
+				Settings: Settings{
+					ModelID:          "model-b",
+					ModelIDAttribute: "fiddler.model.id",
+				},
+				Include: []string{"loan.amount"},
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-b": {{"loan.amount": int64(1)}},
+			},
+		},
+		{
+			name: "unsupported and invalid points are dropped",
+			metrics: func() pmetric.Metrics {
+				md := pmetric.NewMetrics()
+				ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+				nan := ms.AppendEmpty()
+				nan.SetName("nan")
+				nan.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(math.NaN())
+
+				empty := ms.AppendEmpty()
+				empty.SetName("empty_histogram")
+				empty.SetEmptyHistogram().DataPoints().AppendEmpty()
+
+				summary := ms.AppendEmpty()
+				summary.SetName("summary")
+				summary.SetEmptySummary().DataPoints().AppendEmpty()
+				return md
+			},
+			settings: MetricsSettings{
+				Settings: Settings{
+					ModelID: "model-a",
+				},
+			},
+			wantEvents:  map[string][]fiddler.Event{},
+			wantDropped: 3,
+		},
+		{
+			name: "points colliding with attributes or the timestamp column are dropped",
+			metrics: func() pmetric.Metrics {
+				md := pmetric.NewMetrics()
+				ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+				for _, name := range []string{"loan.amount", "region", "event_time"} {
+					m := ms.AppendEmpty()
+					m.SetName(name)
+					dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+					dp.SetTimestamp(ts)
+					dp.SetIntValue(1)
+					dp.Attributes().PutStr("region", "us-east")
+				}
+				return md
+			},
+			settings: MetricsSettings{
+				Settings: Settings{
+					ModelID:         "model-a",
+					TimestampColumn: "event_time",
+				},
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"model-a": {{
+					"loan.amount": int64(1),
+					"region":      "us-east",
+					"event_time":  "2024-06-23T16:00:00Z",
+				}},
+			},
+			wantDropped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, dropped := MetricsToEvents(tt.metrics(), tt.settings)
+			assert.Equal(t, tt.wantEvents, events)
+			assert.Equal(t, tt.wantDropped, dropped)
+		})
+	}
+}
+
+func TestFilterMetrics(t *testing.T) {
+	set := MetricsSettings{
+		Settings: Settings{ModelID: "model-a", ModelIDAttribute: "fiddler.model.id"},
+		Include:  []string{"loan.amount", "loan.latency"},
+	}
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("loan.amount")
+	dps := gauge.SetEmptyGauge().DataPoints()
+	dps.AppendEmpty().SetIntValue(1)
+	dp := dps.AppendEmpty()
+	dp.SetIntValue(2)
+	dp.Attributes().PutStr("fiddler.model.id", "model-b")
+	histogram := ms.AppendEmpty()
+	histogram.SetName("loan.latency")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetCount(2)
+	hdp.SetSum(3)
+	hdp.Attributes().PutStr("fiddler.model.id", "model-b")
+	excluded := ms.AppendEmpty()
+	excluded.SetName("loan.term")
+	excluded.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(3)
+
+	filtered := FilterMetrics(md, set, map[string]struct{}{"model-b": {}})
+	assert.Equal(t, 2, filtered.DataPointCount())
+	events, dropped := MetricsToEvents(filtered, set)
+	assert.Equal(t, map[string][]fiddler.Event{"model-b": {{"loan.amount": int64(2), "loan.latency": 1.5}}}, events)
+	assert.Zero(t, dropped)
+	assert.Equal(t, 4, md.DataPointCount())
+}
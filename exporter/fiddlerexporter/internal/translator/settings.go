@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package translator converts telemetry to events published to Fiddler
+// models.
+package translator // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+
+import "go.opentelemetry.io/collector/pdata/pcommon"
+
+// Settings defines how telemetry is mapped to Fiddler models and columns.
+type Settings struct {
+	// ModelID is the model events are published to when the telemetry has
+	// no ModelIDAttribute.
+	ModelID string
+	// ModelIDAttribute is the attribute holding the ID of the model events
+	// are published to. It is not published as a column.
+	ModelIDAttribute string
+	// TimestampColumn is the column the timestamp is written to, if set.
+	TimestampColumn string
+}
+
+// resourceModelID returns the model of the telemetry of a resource with the
+// attributes attrs, which items can override with their own attribute.
+func (set Settings) resourceModelID(attrs pcommon.Map) string {
+	return set.modelID(set.ModelID, attrs)
+}
+
+// modelID returns the model of an item with the attributes attrs, falling
+// back to the model of its resource.
+func (set Settings) modelID(resourceModelID string, attrs pcommon.Map) string {
+	if v, ok := attrs.Get(set.ModelIDAttribute); ok && set.ModelIDAttribute != "" {
+		return v.AsString()
+	}
+	return resourceModelID
+}
+
+// MetricsSettings defines how metrics are converted to Fiddler events.
+type MetricsSettings struct {
+	Settings
+	// Include lists the names of the metrics to convert. All metrics are
+	// converted when empty.
+	Include []string
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+
+import (
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+const (
+	genAIPrefix = "gen_ai."
+	// durationColumn is the column holding the duration of a GenAI span in
+	// seconds, named after the gen_ai.client.operation.duration metric.
+	durationColumn = "gen_ai.client.operation.duration"
+)
+
+// genAIContentEvents are the span events recording prompts and completions
+// when content capture is enabled in GenAI instrumentations.
+var genAIContentEvents = map[string]struct{}{
+	"gen_ai.content.prompt":     {},
+	"gen_ai.content.completion": {},
+}
+
+// SpansToEvents groups the GenAI spans in td by target model and converts each
+// of them to a Fiddler LLM event. A span is a GenAI span when it has an
+// attribute in the gen_ai namespace; other spans are ignored. Columns are
+// taken from the span attributes, including prompts, completions and token
+// usage, and from the attributes of prompt and completion span events. The
+// duration of the span is added in the durationColumn. GenAI spans for which
+// no model can be resolved or whose columns hold a NaN or infinite number are
+// counted as dropped.
+func SpansToEvents(td ptrace.Traces, set Settings) (map[string][]fiddler.Event, int) {
+	events := make(map[string][]fiddler.Event)
+	dropped := 0
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		rs := td.ResourceSpans().At(i)
+		resourceModelID := set.resourceModelID(rs.Resource().Attributes())
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			for k := 0; k < ss.Spans().Len(); k++ {
+				span := ss.Spans().At(k)
+				if !isGenAISpan(span) {
+					continue
+				}
+
+				modelID := set.modelID(resourceModelID, span.Attributes())
+				if modelID == "" {
+					dropped++
+					continue
+				}
+				event := spanToEvent(span, set)
+				if event == nil {
+					dropped++
+					continue
+				}
+				events[modelID] = append(events[modelID], event)
+			}
+		}
+	}
+	return events, dropped
+}
+
+// FilterSpans returns a copy of td holding only the GenAI spans that
+// SpansToEvents publishes to one of modelIDs, e.g. to retry the spans of the
+// models that failed.
+func FilterSpans(td ptrace.Traces, set Settings, modelIDs map[string]struct{}) ptrace.Traces {
+	filtered := ptrace.NewTraces()
+	td.CopyTo(filtered)
+	filtered.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		resourceModelID := set.resourceModelID(rs.Resource().Attributes())
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(span ptrace.Span) bool {
+				_, ok := modelIDs[set.modelID(resourceModelID, span.Attributes())]
+				return !ok || !isGenAISpan(span)
+			})
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+	return filtered
+}
+
+func isGenAISpan(span ptrace.Span) bool {
+	for k := range span.Attributes().All() {
+		if strings.HasPrefix(k, genAIPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// spanToEvent converts span to an event, or returns nil when one of its
+// columns cannot be published.
+func spanToEvent(span ptrace.Span, set Settings) fiddler.Event {
+	event := make(fiddler.Event)
+	for i := 0; i < span.Events().Len(); i++ {
+		se := span.Events().At(i)
+		if _, ok := genAIContentEvents[se.Name()]; !ok {
+			continue
+		}
+		for k, v := range se.Attributes().All() {
+			if !isFinite(v) {
+				return nil
+			}
+			event[k] = v.AsRaw()
+		}
+	}
+	for k, v := range span.Attributes().All() {
+		if k == set.ModelIDAttribute {
+			continue
+		}
+		if !isFinite(v) {
+			return nil
+		}
+		event[k] = v.AsRaw()
+	}
+	event[durationColumn] = span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime()).Seconds()
+
+	if set.TimestampColumn != "" {
+		event[set.TimestampColumn] = span.StartTimestamp().AsTime().Format(time.RFC3339Nano)
+	}
+	return event
+}
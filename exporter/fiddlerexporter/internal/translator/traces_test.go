@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+func TestSpansToEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		traces      func() ptrace.Traces
+		settings    Settings
+		wantEvents  map[string][]fiddler.Event
+		wantDropped int
+	}{
+		{
+			name: "chat completion",
+			traces: func() ptrace.Traces {
+				td := ptrace.NewTraces()
+				span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				span.SetName("chat gpt-4o")
+				span.SetStartTimestamp(pcommon.Timestamp(1719158400000000000)) // 2024-06-23T16:00:00Z
+				span.SetEndTimestamp(pcommon.Timestamp(1719158401500000000))
+				span.Attributes().PutStr("fiddler.model.id", "llm-app")
+				span.Attributes().PutStr("gen_ai.operation.name", "chat")
+				span.Attributes().PutStr("gen_ai.request.model", "gpt-4o")
+				span.Attributes().PutInt("gen_ai.usage.input_tokens", 12)
+				span.Attributes().PutInt("gen_ai.usage.output_tokens", 30)
+				span.Events().AppendEmpty().SetName("exception")
+				prompt := span.Events().AppendEmpty()
+				prompt.SetName("gen_ai.content.prompt")
+				prompt.Attributes().PutStr("gen_ai.prompt", "What is drift?")
+				completion := span.Events().AppendEmpty()
+				completion.SetName("gen_ai.content.completion")
+				completion.Attributes().PutStr("gen_ai.completion", "A change in data distributions.")
+				return td
+			},
+			settings: Settings{
+				ModelIDAttribute: "fiddler.model.id",
+				TimestampColumn:  "timestamp",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"llm-app": {{
+					"gen_ai.operation.name":      "chat",
+					"gen_ai.request.model":       "gpt-4o",
+					"gen_ai.usage.input_tokens":  int64(12),
+					"gen_ai.usage.output_tokens": int64(30),
+					"gen_ai.prompt":              "What is drift?",
+					"gen_ai.completion":          "A change in data distributions.",
+					durationColumn:               1.5,
+					"timestamp":                  "2024-06-23T16:00:00Z",
+				}},
+			},
+		},
+		{
+			name: "non GenAI spans are ignored",
+			traces: func() ptrace.Traces {
+				td := ptrace.NewTraces()
+				spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+				spans.AppendEmpty().Attributes().PutStr("http.request.method", "GET")
+				spans.AppendEmpty().Attributes().PutStr("gen_ai.operation.name", "embeddings")
+				return td
+			},
+			settings: Settings{
+				ModelID: "llm-app",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"llm-app": {{"gen_ai.operation.name": "embeddings", durationColumn: 0.0}},
+			},
+		},
+		{
+			name: "model resolved from resource",
+			traces: func() ptrace.Traces {
+				td := ptrace.NewTraces()
+				rs := td.ResourceSpans().AppendEmpty()
+				rs.Resource().Attributes().PutStr("fiddler.model.id", "llm-app")
+				rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().Attributes().PutStr("gen_ai.operation.name", "chat")
+				return td
+			},
+			settings: Settings{
+				ModelIDAttribute: "fiddler.model.id",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"llm-app": {{"gen_ai.operation.name": "chat", durationColumn: 0.0}},
+			},
+		},
+		{
+			name: "GenAI spans without model are dropped",
+			traces: func() ptrace.Traces {
+				td := ptrace.NewTraces()
+				td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().Attributes().PutStr("gen_ai.operation.name", "chat")
+				return td
+			},
+			settings: Settings{
+				ModelIDAttribute: "fiddler.model.id",
+			},
+			wantEvents:  map[string][]fiddler.Event{},
+			wantDropped: 1,
+		},
+		{
+			name: "GenAI spans with non-finite numbers are dropped",
+			traces: func() ptrace.Traces {
+				td := ptrace.NewTraces()
+				spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+				spans.AppendEmpty().Attributes().PutDouble("gen_ai.request.temperature", math.NaN())
+				span := spans.AppendEmpty()
+				span.Attributes().PutStr("gen_ai.operation.name", "chat")
+				se := span.Events().AppendEmpty()
+				se.SetName("gen_ai.content.completion")
+				se.Attributes().PutDouble("gen_ai.completion.score", math.Inf(1))
+				spans.AppendEmpty().Attributes().PutDouble("gen_ai.request.temperature", 0.2)
+				return td
+			},
+			settings: Settings{
+				ModelID: "llm-app",
+			},
+			wantEvents: map[string][]fiddler.Event{
+				"llm-app": {{"gen_ai.request.temperature": 0.2, durationColumn: 0.0}},
+			},
+			wantDropped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, dropped := SpansToEvents(tt.traces(), tt.settings)
+			assert.Equal(t, tt.wantEvents, events)
+			assert.Equal(t, tt.wantDropped, dropped)
+		})
+	}
+}
+
+func TestFilterSpans(t *testing.T) {
+	set := Settings{ModelID: "llm-app", ModelIDAttribute: "fiddler.model.id"}
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().Attributes().PutStr("http.request.method", "POST")
+	spans.AppendEmpty().Attributes().PutStr("gen_ai.operation.name", "chat")
+	span := spans.AppendEmpty()
+	span.Attributes().PutStr("gen_ai.operation.name", "embeddings")
+	span.Attributes().PutStr("fiddler.model.id", "embedder")
+
+	filtered := FilterSpans(td, set, map[string]struct{}{"llm-app": {}})
+	assert.Equal(t, 1, filtered.SpanCount())
+	events, _ := SpansToEvents(filtered, set)
+	assert.Equal(t, []string{"llm-app"}, slices.Collect(maps.Keys(events)))
+	assert.Equal(t, 3, td.SpanCount())
+}
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package translator // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/translator"
+
+import (
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// isFinite reports whether v holds no NaN or infinite double, including in
+// nested maps and slices. Non-finite values cannot be encoded as JSON.
+func isFinite(v pcommon.Value) bool {
+	switch v.Type() {
+	case pcommon.ValueTypeDouble:
+		return !math.IsNaN(v.Double()) && !math.IsInf(v.Double(), 0)
+	case pcommon.ValueTypeMap:
+		for _, mv := range v.Map().All() {
+			if !isFinite(mv) {
+				return false
+			}
+		}
+	case pcommon.ValueTypeSlice:
+		for _, sv := range v.Slice().All() {
+			if !isFinite(sv) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+// clientTelemetry reports the measurements of the Fiddler client as the
+// exporter's internal metrics.
+type clientTelemetry struct {
+	builder *metadata.TelemetryBuilder
+}
+
+var _ fiddler.Telemetry = clientTelemetry{}
+
+func (t clientTelemetry) RecordRequest(ctx context.Context, endpoint string, statusCode int, duration time.Duration) {
+	attrs := []attribute.KeyValue{attribute.String("endpoint", endpoint)}
+	if statusCode != 0 {
+		attrs = append(attrs, attribute.Int("status_code", statusCode))
+	}
+	opt := metric.WithAttributes(attrs...)
+	t.builder.FiddlerAPIRequestDuration.Record(ctx, duration.Seconds(), opt)
+	t.builder.FiddlerAPIRequests.Add(ctx, 1, opt)
+
+	if statusCode >= 200 && statusCode < 300 {
+		return
+	}
+	t.builder.FiddlerAPIErrors.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("endpoint", endpoint),
+		attribute.String("status_class", statusClass(statusCode))))
+}
+
+func (t clientTelemetry) RecordCircuitBreakerTrip(ctx context.Context, endpoint string) {
+	t.builder.FiddlerCircuitBreakerTrips.Add(ctx, 1, metric.WithAttributes(attribute.String("endpoint", endpoint)))
+}
+
+func (t clientTelemetry) RecordCircuitBreakerRejection(ctx context.Context, endpoint string) {
+	t.builder.FiddlerCircuitBreakerRejectedRequests.Add(ctx, 1, metric.WithAttributes(attribute.String("endpoint", endpoint)))
+}
+
+// statusClass returns the value of the status_class attribute of an error
+// with statusCode, which is 0 when no response was received.
+func statusClass(statusCode int) string {
+	switch {
+	case statusCode == 0:
+		return "network"
+	case statusCode >= 400 && statusCode < 500:
+		return "4xx"
+	case statusCode >= 500 && statusCode < 600:
+		return "5xx"
+	default:
+		return "other"
+	}
+}
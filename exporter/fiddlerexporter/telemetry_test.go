@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlerexporter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fiddlerexporter/internal/metadatatest"
+)
+
+func TestClientTelemetry(t *testing.T) {
+	tt := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+	tb, err := metadata.NewTelemetryBuilder(tt.NewTelemetrySettings())
+	require.NoError(t, err)
+	defer tb.Shutdown()
+
+	const endpoint = "https://app.fiddler.ai"
+	telemetry := clientTelemetry{builder: tb}
+	telemetry.RecordRequest(t.Context(), endpoint, http.StatusOK, time.Second)
+	telemetry.RecordRequest(t.Context(), endpoint, http.StatusOK, time.Second)
+	telemetry.RecordRequest(t.Context(), endpoint, http.StatusBadGateway, time.Second)
+	telemetry.RecordRequest(t.Context(), endpoint, 0, time.Second)
+	telemetry.RecordRequest(t.Context(), endpoint, http.StatusTooManyRequests, time.Second)
+	telemetry.RecordRequest(t.Context(), endpoint, http.StatusNotModified, time.Second)
+	telemetry.RecordCircuitBreakerTrip(t.Context(), endpoint)
+	telemetry.RecordCircuitBreakerRejection(t.Context(), endpoint)
+	telemetry.RecordCircuitBreakerRejection(t.Context(), endpoint)
+
+	endpointAttrs := attribute.NewSet(attribute.String("endpoint", endpoint))
+	okAttrs := attribute.NewSet(attribute.String("endpoint", endpoint), attribute.Int("status_code", http.StatusOK))
+	errAttrs := attribute.NewSet(attribute.String("endpoint", endpoint), attribute.Int("status_code", http.StatusBadGateway))
+	throttledAttrs := attribute.NewSet(attribute.String("endpoint", endpoint), attribute.Int("status_code", http.StatusTooManyRequests))
+	notModifiedAttrs := attribute.NewSet(attribute.String("endpoint", endpoint), attribute.Int("status_code", http.StatusNotModified))
+	metadatatest.AssertEqualFiddlerAPIRequests(t, tt,
+		[]metricdata.DataPoint[int64]{
+			{Value: 2, Attributes: okAttrs},
+			{Value: 1, Attributes: errAttrs},
+			{Value: 1, Attributes: endpointAttrs},
+			{Value: 1, Attributes: throttledAttrs},
+			{Value: 1, Attributes: notModifiedAttrs},
+		},
+		metricdatatest.IgnoreTimestamp())
+	statusClassAttrs := func(class string) attribute.Set {
+		return attribute.NewSet(attribute.String("endpoint", endpoint), attribute.String("status_class", class))
+	}
+	metadatatest.AssertEqualFiddlerAPIErrors(t, tt,
+		[]metricdata.DataPoint[int64]{
+			{Value: 1, Attributes: statusClassAttrs("5xx")},
+			{Value: 1, Attributes: statusClassAttrs("network")},
+			{Value: 1, Attributes: statusClassAttrs("4xx")},
+			{Value: 1, Attributes: statusClassAttrs("other")},
+		},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualFiddlerCircuitBreakerTrips(t, tt,
+		[]metricdata.DataPoint[int64]{{Value: 1, Attributes: endpointAttrs}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualFiddlerCircuitBreakerRejectedRequests(t, tt,
+		[]metricdata.DataPoint[int64]{{Value: 2, Attributes: endpointAttrs}},
+		metricdatatest.IgnoreTimestamp())
+
+	got, err := tt.GetMetric("otelcol_fiddler_api_request_duration")
+	require.NoError(t, err)
+	histogram, ok := got.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	sums := map[attribute.Set]float64{}
+	for _, dp := range histogram.DataPoints {
+		sums[dp.Attributes] = dp.Sum
+	}
+	assert.Equal(t, map[attribute.Set]float64{
+		okAttrs:          2,
+		errAttrs:         1,
+		endpointAttrs:    1,
+		throttledAttrs:   1,
+		notModifiedAttrs: 1,
+	}, sums)
+}
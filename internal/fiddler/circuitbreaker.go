@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by errors returned when a request is not sent
+// because the circuit breaker of the endpoint is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned when a request is not sent because the
+// circuit breaker of the endpoint is open. It matches ErrCircuitOpen.
+type CircuitOpenError struct {
+	// URL is the URL of the request, with credentials redacted.
+	URL string
+	// RetryAfter is how long until the circuit breaker lets a probe request
+	// through.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("request to %s not sent: %v", e.URL, ErrCircuitOpen)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// circuitBreaker stops sending requests to an endpoint after a number of
+// consecutive failures. Once the cooldown has elapsed, a single probe request
+// is let through: the breaker closes if it succeeds and opens again otherwise.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a request may be sent, and whether it is the probe
+// request. When it may not, it returns how long until the breaker lets a
+// probe request through.
+func (cb *circuitBreaker) allow() (ok, probe bool, wait time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.openUntil.IsZero() {
+		return true, false, 0
+	}
+	if remaining := cb.openUntil.Sub(cb.now()); remaining > 0 {
+		return false, false, remaining
+	}
+	if cb.probing {
+		return false, false, cb.cooldown
+	}
+	cb.probing = true
+	return true, true, 0
+}
+
+// release gives up the probe without recording an outcome, e.g. when the
+// caller cancelled it, so that the next request is let through as the probe.
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+}
+
+// record registers the outcome of a request and reports whether it caused
+// the breaker to open.
+func (cb *circuitBreaker) record(success bool) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if success {
+		cb.failures = 0
+		cb.openUntil = time.Time{}
+		cb.probing = false
+		return false
+	}
+
+	cb.failures++
+	if !cb.probing {
+		// Requests sent before the breaker opened may still fail afterwards.
+		if !cb.openUntil.IsZero() || cb.failures < cb.threshold {
+			return false
+		}
+	}
+	cb.openUntil = cb.now().Add(cb.cooldown)
+	cb.probing = false
+	return true
+}
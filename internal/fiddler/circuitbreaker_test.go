@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	allowed, _, _ := cb.allow()
+	assert.True(t, allowed)
+	assert.False(t, cb.record(false))
+	assert.False(t, cb.record(true), "a success resets the consecutive failures")
+	assert.False(t, cb.record(false))
+	assert.True(t, cb.record(false), "threshold reached")
+	assert.False(t, cb.record(false), "in-flight failures do not trip an open breaker again")
+
+	allowed, _, wait := cb.allow()
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, wait)
+
+	now = now.Add(time.Minute)
+	allowed, probe, _ := cb.allow()
+	assert.True(t, allowed, "probe after the cooldown")
+	assert.True(t, probe)
+	allowed, _, _ = cb.allow()
+	assert.False(t, allowed, "only one probe at a time")
+	cb.release()
+	allowed, probe, _ = cb.allow()
+	assert.True(t, allowed, "released probe lets the next request probe")
+	assert.True(t, probe)
+	assert.True(t, cb.record(false), "failed probe opens the breaker again")
+
+	allowed, _, _ = cb.allow()
+	assert.False(t, allowed)
+
+	now = now.Add(time.Minute)
+	allowed, _, _ = cb.allow()
+	assert.True(t, allowed)
+	assert.False(t, cb.record(true), "successful probe closes the breaker")
+	allowed, _, _ = cb.allow()
+	assert.True(t, allowed)
+}
@@ -0,0 +1,368 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fiddler implements a client for the Fiddler v3 API shared by the
+// Fiddler components.
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.uber.org/zap"
+)
+
+const (
+	eventsPath     = "/v3/events"
+	serverInfoPath = "/v3/server-info"
+
+	headerRetryAfter = "Retry-After"
+	headerRequestID  = "X-Request-ID"
+	contentTypeJSON  = "application/json"
+
+	sourceTypeEvents = "EVENTS"
+
+	// maxLoggedResponseSize is the number of bytes of response bodies logged
+	// when payload logging is enabled.
+	maxLoggedResponseSize = 4096
+	// maxErrorMessageSize is the number of bytes of response bodies kept in
+	// the Message of errors, which end up in retry and error logs.
+	maxErrorMessageSize = 1024
+)
+
+// Event is a single row published to a Fiddler model, keyed by column name.
+type Event map[string]any
+
+type publishSource struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type publishRequest struct {
+	ModelID string        `json:"model_id"`
+	EnvType string        `json:"env_type"`
+	Source  publishSource `json:"source"`
+}
+
+// Client sends requests to the Fiddler API.
+type Client struct {
+	httpClient *http.Client
+	endpoints  []*endpoint
+	token      configopaque.String
+	redactor   redactor
+	userAgent  string
+	telemetry  Telemetry
+	// payloadLogger logs requests and responses when payload logging is
+	// enabled, and is nil otherwise.
+	payloadLogger *zap.Logger
+
+	failoverURLs       []string
+	maxRateLimitWait   time.Duration
+	breakerThreshold   int
+	breakerCooldown    time.Duration
+	withCircuitBreaker bool
+}
+
+// endpoint is a base URL of a Fiddler deployment, with its own circuit breaker.
+type endpoint struct {
+	url string
+	// name is url with credentials redacted, used in errors, logs and
+	// telemetry.
+	name        string
+	breaker     *circuitBreaker
+	rateLimiter *rateLimiter
+}
+
+// Option configures optional behavior of a Client.
+type Option func(*Client)
+
+// WithCircuitBreaker stops sending requests to an endpoint for cooldown after
+// threshold consecutive requests to it failed with a network error or a
+// retryable status.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.withCircuitBreaker = true
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithFailoverEndpoints adds endpoints of the same logical Fiddler deployment
+// that requests are sent to, in order, when the previous ones fail with a
+// network error or a retryable status.
+func WithFailoverEndpoints(urls ...string) Option {
+	return func(c *Client) {
+		c.failoverURLs = append(c.failoverURLs, urls...)
+	}
+}
+
+// WithMaxRateLimitWait sets how long a request waits at most for the rate
+// limit of an endpoint to reset. When the rate limit resets later, the request
+// is sent to the next endpoint or fails with a PausedError.
+func WithMaxRateLimitWait(maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRateLimitWait = maxWait
+	}
+}
+
+// WithPayloadLogging logs the body of every request and the beginning of every
+// response body with logger at debug level. The Authorization header is
+// redacted.
+func WithPayloadLogging(logger *zap.Logger) Option {
+	return func(c *Client) {
+		c.payloadLogger = logger
+	}
+}
+
+// WithTelemetry reports measurements about the requests sent by the client to
+// telemetry.
+func WithTelemetry(telemetry Telemetry) Option {
+	return func(c *Client) {
+		c.telemetry = telemetry
+	}
+}
+
+// New creates a Client issuing requests against url through httpClient.
+func New(httpClient *http.Client, url string, token configopaque.String, userAgent string, opts ...Option) *Client {
+	c := &Client{
+		httpClient: httpClient,
+		token:      token,
+		redactor:   newRedactor(token),
+		userAgent:  userAgent,
+		telemetry:  nopTelemetry{},
+
+		maxRateLimitWait: defaultMaxRateLimitWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, u := range append([]string{url}, c.failoverURLs...) {
+		u = strings.TrimSuffix(u, "/")
+		ep := &endpoint{url: u, name: c.redactor.redactURL(u), rateLimiter: newRateLimiter(c.maxRateLimitWait)}
+		if c.withCircuitBreaker {
+			ep.breaker = newCircuitBreaker(c.breakerThreshold, c.breakerCooldown)
+		}
+		c.endpoints = append(c.endpoints, ep)
+	}
+	return c
+}
+
+// PublishEvents streams events to the given model and environment.
+func (c *Client) PublishEvents(ctx context.Context, modelID, envType string, events []Event) error {
+	body, err := json.Marshal(publishRequest{
+		ModelID: modelID,
+		EnvType: envType,
+		Source: publishSource{
+			Type:   sourceTypeEvents,
+			Events: events,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	return c.send(ctx, http.MethodPost, eventsPath, body)
+}
+
+// ValidateToken issues a lightweight authenticated request, returning an
+// error wrapping ErrUnauthorized if Fiddler rejects the token.
+func (c *Client) ValidateToken(ctx context.Context) error {
+	return c.send(ctx, http.MethodGet, serverInfoPath, nil)
+}
+
+// send issues a request to the first endpoint able to process it. Endpoints
+// are always tried in priority order, so requests fail back to the primary
+// endpoint as soon as it recovers.
+func (c *Client) send(ctx context.Context, method, path string, body []byte) error {
+	var errs error
+	for _, ep := range c.endpoints {
+		err := c.sendTo(ctx, ep, method, path, body)
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		errs = errors.Join(errs, err)
+	}
+	return errs
+}
+
+func (c *Client) sendTo(ctx context.Context, ep *endpoint, method, path string, body []byte) error {
+	target := ep.name + path
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, ep.url+path, reqBody)
+	if err != nil {
+		// The error would include the URL with its credentials.
+		return fmt.Errorf("%w: failed to create request to %s", ErrInvalidRequest, target)
+	}
+
+	remaining, err := ep.rateLimiter.wait(ctx)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return &PausedError{URL: target, RetryAfter: remaining}
+	}
+
+	// Once the breaker allowed a request, its outcome must be recorded, or a
+	// probe request must be released, or the breaker would stay half-open
+	// for good.
+	probe := false
+	if ep.breaker != nil {
+		var ok bool
+		var wait time.Duration
+		if ok, probe, wait = ep.breaker.allow(); !ok {
+			c.telemetry.RecordCircuitBreakerRejection(ctx, ep.name)
+			return &CircuitOpenError{URL: target, RetryAfter: wait}
+		}
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", contentTypeJSON)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+string(c.token))
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	c.logRequest(req, target, body)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.recordRequest(ctx, ep, start, resp)
+	if err != nil {
+		switch {
+		case ctx.Err() == nil:
+			c.recordOutcome(ctx, ep, false)
+		case probe:
+			// The caller gave up on the request, which says nothing about
+			// the health of the endpoint.
+			ep.breaker.release()
+		}
+		return err
+	}
+	defer func() {
+		// Drain the response body to avoid leaking resources.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	c.recordOutcome(ctx, ep, !isRetryableStatusCode(resp.StatusCode))
+	ep.rateLimiter.update(resp)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if c.payloadLogger != nil {
+			respBody, _ := io.ReadAll(resp.Body)
+			c.logResponse(target, resp.StatusCode, respBody)
+		}
+		return nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	c.logResponse(target, resp.StatusCode, respBody)
+	apiErr := &APIError{
+		URL:        target,
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(headerRequestID),
+		Message:    truncateMessage(c.redactor.redact(string(respBody))),
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &AuthError{APIError: apiErr}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		rateLimitErr := &RateLimitError{APIError: apiErr}
+		if delay, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter), time.Now()); ok {
+			rateLimitErr.RetryAfter = delay
+		}
+		return rateLimitErr
+	case !isRetryableStatusCode(resp.StatusCode):
+		return &QueryError{APIError: apiErr}
+	default:
+		return apiErr
+	}
+}
+
+func (c *Client) logRequest(req *http.Request, target string, body []byte) {
+	if c.payloadLogger == nil {
+		return
+	}
+	headers := req.Header.Clone()
+	if headers.Get("Authorization") != "" {
+		headers.Set("Authorization", redacted)
+	}
+	c.payloadLogger.Debug("Sending request to Fiddler",
+		zap.String("method", req.Method),
+		zap.String("url", target),
+		zap.Any("headers", headers),
+		zap.String("body", c.redactor.redact(string(body))))
+}
+
+func (c *Client) logResponse(target string, statusCode int, body []byte) {
+	if c.payloadLogger == nil {
+		return
+	}
+	// Credentials are redacted before truncating, as a credential cut in
+	// half would no longer be recognized.
+	redactedBody := c.redactor.redact(string(body))
+	truncated := len(redactedBody) > maxLoggedResponseSize
+	if truncated {
+		redactedBody = redactedBody[:maxLoggedResponseSize]
+	}
+	c.payloadLogger.Debug("Received response from Fiddler",
+		zap.String("url", target),
+		zap.Int("status_code", statusCode),
+		zap.String("body", redactedBody),
+		zap.Bool("truncated", truncated))
+}
+
+// truncateMessage shortens a redacted response body to be used as the
+// message of an error.
+func truncateMessage(message string) string {
+	if len(message) <= maxErrorMessageSize {
+		return message
+	}
+	return message[:maxErrorMessageSize] + "...(truncated)"
+}
+
+// recordRequest records the duration and status code of a request sent to ep.
+// resp is nil when no response was received.
+func (c *Client) recordRequest(ctx context.Context, ep *endpoint, start time.Time, resp *http.Response) {
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	c.telemetry.RecordRequest(ctx, ep.name, statusCode, time.Since(start))
+}
+
+// recordOutcome feeds the circuit breaker of ep. Non-retryable errors such as
+// rejected payloads count as successes, as the endpoint is healthy.
+func (c *Client) recordOutcome(ctx context.Context, ep *endpoint, success bool) {
+	if ep.breaker == nil {
+		return
+	}
+	if ep.breaker.record(success) {
+		c.telemetry.RecordCircuitBreakerTrip(ctx, ep.name)
+	}
+}
+
+func isRetryableStatusCode(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,492 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPublishEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		retryAfter    string
+		wantErr       string
+		wantRetryable bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusAccepted,
+		},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			wantErr: "responded with HTTP Status Code 401",
+		},
+		{
+			name:          "throttled",
+			status:        http.StatusTooManyRequests,
+			retryAfter:    "10",
+			wantErr:       "responded with HTTP Status Code 429",
+			wantRetryable: true,
+		},
+		{
+			name:          "server error",
+			status:        http.StatusBadGateway,
+			wantErr:       "responded with HTTP Status Code 502",
+			wantRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v3/events", r.URL.Path)
+				assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("X-Request-ID", "req-123")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			c := New(server.Client(), server.URL+"/", "test-token", "test-agent")
+			err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantRetryable, isRetryable(err))
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "req-123", apiErr.RequestID)
+		})
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				require.ErrorAs(t, err, &authErr)
+				assert.ErrorIs(t, err, ErrUnauthorized)
+			},
+		},
+		{
+			name:   "forbidden",
+			status: http.StatusForbidden,
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				require.ErrorAs(t, err, &authErr)
+				assert.ErrorIs(t, err, ErrUnauthorized)
+			},
+		},
+		{
+			name:       "throttled",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "10",
+			check: func(t *testing.T, err error) {
+				var rateLimitErr *RateLimitError
+				require.ErrorAs(t, err, &rateLimitErr)
+				assert.Equal(t, 10*time.Second, rateLimitErr.RetryAfter)
+			},
+		},
+		{
+			name:   "unavailable without Retry-After",
+			status: http.StatusServiceUnavailable,
+			check: func(t *testing.T, err error) {
+				var rateLimitErr *RateLimitError
+				require.ErrorAs(t, err, &rateLimitErr)
+				assert.Zero(t, rateLimitErr.RetryAfter)
+			},
+		},
+		{
+			name:   "rejected request",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, err error) {
+				var queryErr *QueryError
+				require.ErrorAs(t, err, &queryErr)
+				assert.NotErrorIs(t, err, ErrUnauthorized)
+			},
+		},
+		{
+			name:   "server error",
+			status: http.StatusBadGateway,
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				var rateLimitErr *RateLimitError
+				var queryErr *QueryError
+				assert.False(t, errors.As(err, &authErr) || errors.As(err, &rateLimitErr) || errors.As(err, &queryErr))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("X-Request-ID", "req-123")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			c := New(server.Client(), server.URL, "test-token", "test-agent")
+			err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}})
+			tt.check(t, err)
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "req-123", apiErr.RequestID)
+		})
+	}
+}
+
+func TestEndpointPathPrefix(t *testing.T) {
+	for _, prefix := range []string{"/fiddler", "/fiddler/"} {
+		t.Run(prefix, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			c := New(server.Client(), server.URL+prefix, "test-token", "test-agent")
+			require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}}))
+			assert.Equal(t, "/fiddler/v3/events", gotPath)
+		})
+	}
+}
+
+func TestFailover(t *testing.T) {
+	var primaryStatus atomic.Int32
+	var primaryRequests, secondaryRequests atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryRequests.Add(1)
+		w.WriteHeader(int(primaryStatus.Load()))
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		secondaryRequests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	c := New(http.DefaultClient, primary.URL, "test-token", "test-agent",
+		WithFailoverEndpoints(secondary.URL), WithCircuitBreaker(1, time.Hour))
+	events := []Event{{"age": 42}}
+
+	primaryStatus.Store(http.StatusBadRequest)
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events)
+	assert.False(t, isRetryable(err), "rejected payloads are not sent to failover endpoints")
+	assert.Equal(t, int32(0), secondaryRequests.Load())
+
+	primaryStatus.Store(http.StatusServiceUnavailable)
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Equal(t, int32(2), primaryRequests.Load())
+	assert.Equal(t, int32(1), secondaryRequests.Load())
+
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Equal(t, int32(2), primaryRequests.Load(), "open circuit breaker skips the primary endpoint")
+	assert.Equal(t, int32(2), secondaryRequests.Load())
+
+	// Fail back to the primary endpoint once its circuit breaker lets a probe through.
+	primaryStatus.Store(http.StatusOK)
+	c.endpoints[0].breaker.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Equal(t, int32(3), primaryRequests.Load())
+	assert.Equal(t, int32(2), secondaryRequests.Load())
+}
+
+func TestCircuitBreakerProbeAfterCancelledRateLimitWait(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if status.Load() == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "5")
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	c := New(server.Client(), server.URL, "test-token", "test-agent", WithCircuitBreaker(1, time.Second))
+	ep := c.endpoints[0]
+	events := []Event{{"age": 42}}
+
+	// Open the circuit breaker and pause requests for the rate limit.
+	status.Store(http.StatusServiceUnavailable)
+	require.Error(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	ep.breaker.now = func() time.Time { return time.Now().Add(time.Minute) }
+
+	// The request is cancelled while waiting for the rate limit to reset,
+	// when the breaker would let a probe request through.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.PublishEvents(ctx, "model-a", "PRODUCTION", events), context.DeadlineExceeded)
+
+	// The probe request is still let through once the rate limit reset.
+	ep.rateLimiter.now = func() time.Time { return time.Now().Add(time.Minute) }
+	status.Store(http.StatusOK)
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+}
+
+func TestCancelledRequestsDoNotTripCircuitBreaker(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status.Load() == 0 {
+			// Hang until the caller gives up on the request, which the
+			// server only notices once the request body was read.
+			_, _ = io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	telemetry := &recordingTelemetry{}
+	c := New(server.Client(), server.URL, "test-token", "test-agent",
+		WithCircuitBreaker(1, time.Second), WithTelemetry(telemetry))
+	ep := c.endpoints[0]
+	events := []Event{{"age": 42}}
+	publishCancelled := func() {
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, c.PublishEvents(ctx, "model-a", "PRODUCTION", events), context.DeadlineExceeded)
+	}
+
+	publishCancelled()
+	assert.Empty(t, telemetry.trips, "a cancelled request is not a failure of the endpoint")
+
+	// Open the circuit breaker, and cancel the probe request let through
+	// once the cooldown elapsed.
+	status.Store(http.StatusBadGateway)
+	require.Error(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Len(t, telemetry.trips, 1)
+	ep.breaker.now = func() time.Time { return time.Now().Add(time.Minute) }
+	status.Store(0)
+	publishCancelled()
+	assert.Len(t, telemetry.trips, 1)
+
+	// The cancelled probe was released, so the next request probes.
+	status.Store(http.StatusOK)
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Empty(t, telemetry.rejections)
+}
+
+func TestRateLimitFailover(t *testing.T) {
+	var primaryRequests, secondaryRequests atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryRequests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		secondaryRequests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	c := New(http.DefaultClient, primary.URL, "test-token", "test-agent",
+		WithFailoverEndpoints(secondary.URL), WithMaxRateLimitWait(time.Second))
+	events := []Event{{"age": 42}}
+
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Equal(t, int32(1), primaryRequests.Load())
+	assert.Equal(t, int32(1), secondaryRequests.Load())
+
+	// The primary endpoint is paused for longer than the client waits, so
+	// requests go to the failover endpoint without blocking.
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Equal(t, int32(1), primaryRequests.Load())
+	assert.Equal(t, int32(2), secondaryRequests.Load())
+
+	// Without a failover endpoint, the pause is returned to the caller.
+	c = New(http.DefaultClient, primary.URL, "test-token", "test-agent", WithMaxRateLimitWait(time.Second))
+	require.Error(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events)
+	require.ErrorIs(t, err, ErrPaused)
+	var pausedErr *PausedError
+	require.ErrorAs(t, err, &pausedErr)
+	assert.Greater(t, pausedErr.RetryAfter, 59*time.Minute)
+	assert.Equal(t, int32(2), primaryRequests.Load())
+}
+
+func TestPayloadLogging(t *testing.T) {
+	largeMessage := strings.Repeat("x", maxLoggedResponseSize+1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(largeMessage))
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zap.DebugLevel)
+	c := New(server.Client(), server.URL, "secret-token", "test-agent", WithPayloadLogging(zap.New(core)))
+	require.Error(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}}))
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+
+	request := entries[0].ContextMap()
+	assert.Equal(t, "Sending request to Fiddler", entries[0].Message)
+	assert.Equal(t, server.URL+"/v3/events", request["url"])
+	assert.JSONEq(t, `{"model_id":"model-a","env_type":"PRODUCTION","source":{"type":"EVENTS","events":[{"age":42}]}}`, request["body"].(string))
+	assert.Equal(t, []string{"[REDACTED]"}, request["headers"].(http.Header)["Authorization"])
+	assert.NotContains(t, fmt.Sprint(request), "secret-token")
+
+	response := entries[1].ContextMap()
+	assert.Equal(t, "Received response from Fiddler", entries[1].Message)
+	assert.Equal(t, int64(http.StatusBadRequest), response["status_code"])
+	assert.Equal(t, largeMessage[:maxLoggedResponseSize], response["body"])
+	assert.Equal(t, true, response["truncated"])
+}
+
+func TestTruncatedResponsesDoNotLeakCredentials(t *testing.T) {
+	// The token straddles the truncation of both the logged body and the
+	// error message.
+	const token = "secret-token"
+	body := strings.Repeat("x", maxErrorMessageSize-4) + token +
+		strings.Repeat("x", maxLoggedResponseSize-maxErrorMessageSize-len(token)) + token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zap.DebugLevel)
+	c := New(server.Client(), server.URL, token, "test-agent", WithPayloadLogging(zap.New(core)))
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Len(t, apiErr.Message, maxErrorMessageSize+len("...(truncated)"))
+	assert.NotContains(t, apiErr.Message, "secr")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	loggedBody := entries[1].ContextMap()["body"].(string)
+	assert.Len(t, loggedBody, maxLoggedResponseSize)
+	assert.NotContains(t, loggedBody, "secr")
+}
+
+func TestErrorsDoNotLeakCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("invalid " + r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	withPassword := strings.Replace(server.URL, "http://", "http://user:password@", 1)
+	c := New(server.Client(), withPassword, "secret-token", "test-agent")
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []Event{{"age": 42}})
+	require.ErrorIs(t, err, ErrUnauthorized)
+	assert.ErrorContains(t, err, "invalid Bearer [REDACTED]")
+	assert.NotContains(t, err.Error(), "secret-token")
+	assert.NotContains(t, err.Error(), "password")
+}
+
+func TestCircuitBreakerTelemetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	telemetry := &recordingTelemetry{}
+	c := New(server.Client(), server.URL, "test-token", "test-agent",
+		WithCircuitBreaker(2, time.Hour), WithTelemetry(telemetry))
+	events := []Event{{"age": 42}}
+
+	assert.ErrorContains(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events), "503")
+	assert.ErrorContains(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events), "503")
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var circuitErr *CircuitOpenError
+	require.ErrorAs(t, err, &circuitErr)
+	assert.Equal(t, server.URL+"/v3/events", circuitErr.URL)
+	assert.Greater(t, circuitErr.RetryAfter, 59*time.Minute)
+
+	assert.Equal(t, []string{server.URL}, telemetry.trips)
+	assert.Equal(t, []string{server.URL}, telemetry.rejections)
+}
+
+func TestRequestTelemetry(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	telemetry := &recordingTelemetry{}
+	c := New(server.Client(), server.URL, "test-token", "test-agent",
+		WithFailoverEndpoints("http://127.0.0.1:0"), WithTelemetry(telemetry))
+	events := []Event{{"age": 42}}
+
+	status.Store(http.StatusOK)
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	status.Store(http.StatusBadGateway)
+	require.Error(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+
+	assert.Equal(t, []string{
+		server.URL + " 200",
+		server.URL + " 502",
+		"http://127.0.0.1:0 0",
+	}, telemetry.requests)
+}
+
+// recordingTelemetry records the endpoints, and the status codes of
+// requests, reported to it.
+type recordingTelemetry struct {
+	mu         sync.Mutex
+	requests   []string
+	trips      []string
+	rejections []string
+}
+
+func (r *recordingTelemetry) RecordRequest(_ context.Context, endpoint string, statusCode int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, fmt.Sprintf("%s %d", endpoint, statusCode))
+}
+
+func (r *recordingTelemetry) RecordCircuitBreakerTrip(_ context.Context, endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trips = append(r.trips, endpoint)
+}
+
+func (r *recordingTelemetry) RecordCircuitBreakerRejection(_ context.Context, endpoint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejections = append(r.rejections, endpoint)
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnauthorized is matched by errors returned when Fiddler rejects the API
+// token, either because it is invalid or because it lacks the required
+// permissions.
+var ErrUnauthorized = errors.New("invalid or unauthorized API token")
+
+// ErrInvalidRequest is matched by errors returned when a request cannot be
+// built, e.g. events that cannot be encoded. Retrying it cannot succeed.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// APIError is returned when Fiddler responds with an unsuccessful status
+// code. Responses to which callers need to react differently are returned as
+// an AuthError, RateLimitError or QueryError wrapping an APIError.
+type APIError struct {
+	// URL is the URL of the request, with credentials redacted.
+	URL        string
+	StatusCode int
+	// RequestID identifies the request in Fiddler logs. It is empty when the
+	// response has no request ID header.
+	RequestID string
+	// Message is the response body.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("request to %s responded with HTTP Status Code %d, Message=%s",
+			e.URL, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("request to %s responded with HTTP Status Code %d, RequestID=%s, Message=%s",
+		e.URL, e.StatusCode, e.RequestID, e.Message)
+}
+
+// AuthError is returned when Fiddler rejects the credentials with a 401 or
+// 403 status code. It matches ErrUnauthorized.
+type AuthError struct {
+	*APIError
+}
+
+func (e *AuthError) Error() string {
+	return ErrUnauthorized.Error() + ": " + e.APIError.Error()
+}
+
+func (e *AuthError) Unwrap() []error {
+	return []error{ErrUnauthorized, e.APIError}
+}
+
+// RateLimitError is returned when Fiddler throttles a request with a 429 or
+// 503 status code.
+type RateLimitError struct {
+	*APIError
+	// RetryAfter is the delay requested by the Retry-After header, or zero
+	// when the response has none.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// QueryError is returned when Fiddler rejects the request itself, e.g. events
+// that do not match the model schema, with a status code that retrying
+// cannot change.
+type QueryError struct {
+	*APIError
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.APIError
+}
+
+// isRetryable reports whether sending a request that failed with err again,
+// or to another endpoint, may succeed.
+func isRetryable(err error) bool {
+	var authErr *AuthError
+	var queryErr *QueryError
+	return !errors.Is(err, ErrInvalidRequest) && !errors.As(err, &authErr) && !errors.As(err, &queryErr)
+}
@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fiddlertest provides an in-memory fake of the Fiddler v3 API for
+// tests of the Fiddler components. It only serves the endpoints called by
+// fiddler.Client: publishing events and server-info.
+package fiddlertest // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler/fiddlertest"
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+const (
+	eventsPath     = "/v3/events"
+	serverInfoPath = "/v3/server-info"
+)
+
+// Batch is a set of events published to the Fiddler API in one request.
+type Batch struct {
+	ModelID string
+	EnvType string
+	Events  []fiddler.Event
+	// Header holds the headers of the request.
+	Header http.Header
+}
+
+// Server is a fake Fiddler API serving the requests sent by fiddler.Client.
+// It records published events, and can be told to fail requests.
+type Server struct {
+	*httptest.Server
+
+	token string
+
+	mu        sync.Mutex
+	batches   []Batch
+	failures  []failure
+	requestID int
+}
+
+type failure struct {
+	statusCode int
+	header     http.Header
+	message    string
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithToken rejects requests that are not authenticated with token.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// NewServer starts a Server. Callers must call Close when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Batches returns the batches published so far, in order.
+func (s *Server) Batches() []Batch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Batch(nil), s.batches...)
+}
+
+// Events returns the events published so far to modelID, in order.
+func (s *Server) Events(modelID string) []fiddler.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []fiddler.Event
+	for _, b := range s.batches {
+		if b.ModelID == modelID {
+			events = append(events, b.Events...)
+		}
+	}
+	return events
+}
+
+// FailNext responds to the next n requests with statusCode. header is added
+// to the responses, e.g. to set Retry-After.
+func (s *Server) FailNext(n, statusCode int, header http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, failure{
+			statusCode: statusCode,
+			header:     header,
+			message:    http.StatusText(statusCode),
+		})
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requestID++
+	w.Header().Set("X-Request-ID", fmt.Sprintf("fiddlertest-%d", s.requestID))
+	var f *failure
+	if len(s.failures) > 0 {
+		f = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if f != nil {
+		for k, v := range f.header {
+			w.Header()[k] = v
+		}
+		http.Error(w, f.message, f.statusCode)
+		return
+	}
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "invalid API token", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case eventsPath:
+		s.handleEvents(w, r)
+	case serverInfoPath:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"server_version": "fiddlertest"}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		ModelID string `json:"model_id"`
+		EnvType string `json:"env_type"`
+		Source  struct {
+			Type   string          `json:"type"`
+			Events []fiddler.Event `json:"events"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ModelID == "" || req.EnvType == "" || req.Source.Type != "EVENTS" {
+		http.Error(w, "model_id, env_type and an EVENTS source are required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.batches = append(s.batches, Batch{
+		ModelID: req.ModelID,
+		EnvType: req.EnvType,
+		Events:  req.Source.Events,
+		Header:  r.Header.Clone(),
+	})
+	s.mu.Unlock()
+	writeJSON(w, http.StatusAccepted, map[string]any{"data": map[string]any{}})
+}
+
+// readBody reads the request body, decompressing it according to its
+// Content-Encoding as set by confighttp compression.
+func readBody(r *http.Request) ([]byte, error) {
+	switch encoding := strings.ToLower(r.Header.Get("Content-Encoding")); encoding {
+	case "", "identity":
+		return io.ReadAll(r.Body)
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlertest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer(WithToken("test-token"))
+	defer server.Close()
+	c := fiddler.New(server.Client(), server.URL, "test-token", "test-agent")
+
+	require.NoError(t, c.ValidateToken(t.Context()))
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []fiddler.Event{{"age": 42.0}}))
+	require.NoError(t, c.PublishEvents(t.Context(), "model-b", "PRE_PRODUCTION", []fiddler.Event{{"age": 7.0}}))
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []fiddler.Event{{"age": 43.0}}))
+
+	batches := server.Batches()
+	require.Len(t, batches, 3)
+	assert.Equal(t, "model-b", batches[1].ModelID)
+	assert.Equal(t, "PRE_PRODUCTION", batches[1].EnvType)
+	assert.Equal(t, "test-agent", batches[1].Header.Get("User-Agent"))
+	assert.Equal(t, []fiddler.Event{{"age": 42.0}, {"age": 43.0}}, server.Events("model-a"))
+}
+
+func TestServerRejectsInvalidToken(t *testing.T) {
+	server := NewServer(WithToken("test-token"))
+	defer server.Close()
+	c := fiddler.New(server.Client(), server.URL, "other-token", "test-agent")
+
+	require.ErrorIs(t, c.ValidateToken(t.Context()), fiddler.ErrUnauthorized)
+	require.ErrorIs(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", []fiddler.Event{{"age": 42}}), fiddler.ErrUnauthorized)
+	assert.Empty(t, server.Batches())
+}
+
+func TestServerFailNext(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	c := fiddler.New(server.Client(), server.URL, "test-token", "test-agent")
+	events := []fiddler.Event{{"age": 42}}
+
+	server.FailNext(1, http.StatusBadRequest, nil)
+	server.FailNext(1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}})
+
+	err := c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events)
+	var apiErr *fiddler.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "fiddlertest-1", apiErr.RequestID)
+	var queryErr *fiddler.QueryError
+	assert.ErrorAs(t, err, &queryErr)
+
+	err = c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	var rateLimitErr *fiddler.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+
+	require.NoError(t, c.PublishEvents(t.Context(), "model-a", "PRODUCTION", events))
+	assert.Len(t, server.Batches(), 1)
+}
+
+func TestServerCompressedRequest(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(`{"model_id":"model-a","env_type":"PRODUCTION","source":{"type":"EVENTS","events":[{"age":42}]}}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/v3/events", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, []fiddler.Event{{"age": 42.0}}, server.Events("model-a"))
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+
+	// Reset values above this are Unix timestamps rather than a number of
+	// seconds, as both conventions are in use.
+	minUnixReset = 1_000_000_000
+
+	// defaultMaxRateLimitWait is how long requests wait for a rate limit
+	// pause at most, unless set with WithMaxRateLimitWait.
+	defaultMaxRateLimitWait = 30 * time.Second
+)
+
+// ErrPaused is matched by errors returned when a request is not sent because
+// the rate limit of the endpoint is exhausted for longer than the client
+// waits.
+var ErrPaused = errors.New("requests are paused by the rate limit")
+
+// PausedError is returned when a request is not sent because the rate limit
+// of the endpoint is exhausted for longer than the client waits. It matches
+// ErrPaused.
+type PausedError struct {
+	// URL is the URL of the request, with credentials redacted.
+	URL string
+	// RetryAfter is how long until the rate limit resets.
+	RetryAfter time.Duration
+}
+
+func (e *PausedError) Error() string {
+	return fmt.Sprintf("request to %s not sent: %v for %v", e.URL, ErrPaused, e.RetryAfter)
+}
+
+func (e *PausedError) Is(target error) bool {
+	return target == ErrPaused
+}
+
+// rateLimiter paces the requests sent to an endpoint according to the rate
+// limiting headers of its responses, so that once the limit is exhausted
+// requests wait for it to reset instead of being rejected. Pauses longer than
+// maxWait are not waited for, so that callers are not blocked indefinitely
+// and can use another endpoint instead.
+type rateLimiter struct {
+	now     func() time.Time
+	maxWait time.Duration
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func newRateLimiter(maxWait time.Duration) *rateLimiter {
+	return &rateLimiter{now: time.Now, maxWait: maxWait}
+}
+
+// wait blocks until requests may be sent again or ctx is done. When requests
+// are paused for longer than maxWait, it returns the remaining pause
+// immediately instead.
+func (rl *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	delay := rl.delay()
+	if delay <= 0 {
+		return 0, nil
+	}
+	if delay > rl.maxWait {
+		return delay, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return 0, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (rl *rateLimiter) delay() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.pausedUntil.Sub(rl.now())
+}
+
+// update pauses requests according to resp. It honors Retry-After on
+// throttling responses, and X-RateLimit-Reset once X-RateLimit-Remaining
+// reached zero.
+func (rl *rateLimiter) update(resp *http.Response) {
+	now := rl.now()
+	var until time.Time
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if d, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter), now); ok {
+			until = now.Add(d)
+		}
+	}
+	if resp.Header.Get(headerRateLimitRemaining) == "0" {
+		if reset, ok := parseRateLimitReset(resp.Header.Get(headerRateLimitReset), now); ok && reset.After(until) {
+			until = reset
+		}
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if until.After(rl.pausedUntil) {
+		rl.pausedUntil = until
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds >= 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses an X-RateLimit-Reset header value, either a
+// number of seconds or a Unix timestamp.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds >= minUnixReset {
+		return time.Unix(seconds, 0), true
+	}
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
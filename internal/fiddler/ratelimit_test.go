@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 6, 23, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    int
+		headers   map[string]string
+		wantDelay time.Duration
+	}{
+		{
+			name:   "no rate limit headers",
+			status: http.StatusOK,
+		},
+		{
+			name:      "retry after seconds",
+			status:    http.StatusTooManyRequests,
+			headers:   map[string]string{"Retry-After": "30"},
+			wantDelay: 30 * time.Second,
+		},
+		{
+			name:      "retry after date",
+			status:    http.StatusServiceUnavailable,
+			headers:   map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)},
+			wantDelay: time.Minute,
+		},
+		{
+			name:   "retry after ignored on other statuses",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"Retry-After": "30",
+			},
+		},
+		{
+			name:   "remaining requests",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "5",
+				"X-RateLimit-Reset":     "30",
+			},
+		},
+		{
+			name:   "exhausted with reset in seconds",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "20",
+			},
+			wantDelay: 20 * time.Second,
+		},
+		{
+			name:   "exhausted with reset as unix timestamp",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "1719158445",
+			},
+			wantDelay: 45 * time.Second,
+		},
+		{
+			name:   "longest of retry after and reset",
+			status: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"Retry-After":           "10",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "40",
+			},
+			wantDelay: 40 * time.Second,
+		},
+		{
+			name:   "invalid values",
+			status: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"Retry-After":           "soon",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := newRateLimiter(time.Minute)
+			rl.now = func() time.Time { return now }
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			rl.update(resp)
+			assert.Equal(t, tt.wantDelay, max(rl.delay(), 0))
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	rl := newRateLimiter(time.Minute)
+	remaining, err := rl.wait(t.Context())
+	assert.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	rl.pausedUntil = time.Now().Add(30 * time.Second)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err = rl.wait(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	rl.pausedUntil = time.Now().Add(time.Hour)
+	remaining, err = rl.wait(t.Context())
+	assert.NoError(t, err)
+	assert.Greater(t, remaining, 59*time.Minute, "pauses longer than maxWait are not waited for")
+}
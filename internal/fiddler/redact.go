@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+const redacted = "[REDACTED]"
+
+// redactor removes credentials from text before it is logged, returned in an
+// error or recorded in telemetry. Fiddler may for instance echo the token in
+// the body of an error response. Only the token given to New is known to the
+// client: credentials added by the http.Client transport, such as those of
+// an auth extension, are not redacted.
+type redactor struct {
+	replacer *strings.Replacer
+}
+
+func newRedactor(token configopaque.String) redactor {
+	if token == "" {
+		return redactor{}
+	}
+	return redactor{replacer: strings.NewReplacer(string(token), redacted)}
+}
+
+func (r redactor) redact(s string) string {
+	if r.replacer == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// redactURL replaces the password of rawURL, if any, and any occurrence of
+// the token.
+func (r redactor) redactURL(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		rawURL = u.Redacted()
+	}
+	return r.redact(rawURL)
+}
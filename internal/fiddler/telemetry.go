@@ -0,0 +1,31 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddler // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/fiddler"
+
+import (
+	"context"
+	"time"
+)
+
+// Telemetry receives measurements about the requests sent by a Client, so
+// that components can report them in their internal telemetry.
+type Telemetry interface {
+	// RecordRequest is called once a request to endpoint completed.
+	// statusCode is 0 when no response was received.
+	RecordRequest(ctx context.Context, endpoint string, statusCode int, duration time.Duration)
+	// RecordCircuitBreakerTrip is called when the circuit breaker of
+	// endpoint opens.
+	RecordCircuitBreakerTrip(ctx context.Context, endpoint string)
+	// RecordCircuitBreakerRejection is called when a request is not sent
+	// because the circuit breaker of endpoint is open.
+	RecordCircuitBreakerRejection(ctx context.Context, endpoint string)
+}
+
+type nopTelemetry struct{}
+
+func (nopTelemetry) RecordRequest(context.Context, string, int, time.Duration) {}
+
+func (nopTelemetry) RecordCircuitBreakerTrip(context.Context, string) {}
+
+func (nopTelemetry) RecordCircuitBreakerRejection(context.Context, string) {}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlersamplerprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fiddlersamplerprocessor"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// ModelConfig overrides the sampling percentage of a Fiddler model.
+type ModelConfig struct {
+	// ModelID is the ID of the Fiddler model.
+	ModelID string `mapstructure:"model_id"`
+	// SamplingPercentage is the percentage of the model's log records and
+	// spans that are kept.
+	SamplingPercentage float64 `mapstructure:"sampling_percentage"`
+
+	_ struct{}
+}
+
+// Config defines configuration for the Fiddler sampler processor.
+type Config struct {
+	// ModelIDAttribute is the record, span or resource attribute holding the
+	// ID of the Fiddler model the telemetry belongs to.
+	ModelIDAttribute string `mapstructure:"model_id_attribute"`
+	// SamplingPercentage is the percentage of log records and spans kept
+	// for models without an entry in Models.
+	SamplingPercentage float64 `mapstructure:"sampling_percentage"`
+	// Models overrides SamplingPercentage for individual models.
+	Models []ModelConfig `mapstructure:"models"`
+
+	_ struct{}
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.ModelIDAttribute == "" {
+		return errors.New("model_id_attribute must not be empty")
+	}
+	if err := validatePercentage(cfg.SamplingPercentage); err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(cfg.Models))
+	for i, model := range cfg.Models {
+		if model.ModelID == "" {
+			return fmt.Errorf("models[%d]: model_id must not be empty", i)
+		}
+		if _, ok := seen[model.ModelID]; ok {
+			return fmt.Errorf("models[%d]: duplicate model_id %q", i, model.ModelID)
+		}
+		seen[model.ModelID] = struct{}{}
+		if err := validatePercentage(model.SamplingPercentage); err != nil {
+			return fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validatePercentage(percentage float64) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("sampling_percentage must be between 0 and 100, got %v", percentage)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlersamplerprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/fiddlersamplerprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		id           component.ID
+		expected     *Config
+		errorMessage string
+	}{
+		{
+			id:       component.NewIDWithName(metadata.Type, ""),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "models"),
+			expected: &Config{
+				ModelIDAttribute:   "ml.model.id",
+				SamplingPercentage: 50,
+				Models: []ModelConfig{
+					{ModelID: "credit-model", SamplingPercentage: 1},
+					{ModelID: "fraud-model", SamplingPercentage: 25},
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_percentage"),
+			errorMessage: "sampling_percentage must be between 0 and 100, got 150",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "missing_model_id"),
+			errorMessage: "models[0]: model_id must not be empty",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "duplicate_model"),
+			errorMessage: `models[1]: duplicate model_id "credit-model"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_model_percentage"),
+			errorMessage: "models[0]: sampling_percentage must be between 0 and 100, got -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expected == nil {
+				assert.EqualError(t, xconfmap.Validate(cfg), tt.errorMessage)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
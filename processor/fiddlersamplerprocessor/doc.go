@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package fiddlersamplerprocessor defines a processor sampling the log
+// records and spans published to Fiddler, with a rate per model.
+package fiddlersamplerprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fiddlersamplerprocessor"
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlersamplerprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fiddlersamplerprocessor"
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand/v2"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+// randomnessMask keeps the 56 rightmost bits of a trace ID, which are random
+// in W3C trace context version 2.
+const randomnessMask = 1<<56 - 1
+
+type sampler struct {
+	modelIDAttribute   string
+	samplingPercentage float64
+	models             map[string]float64
+	// random returns a number in [0, 1), used for telemetry without a trace
+	// ID.
+	random func() float64
+}
+
+func newSampler(cfg *Config) *sampler {
+	models := make(map[string]float64, len(cfg.Models))
+	for _, model := range cfg.Models {
+		models[model.ModelID] = model.SamplingPercentage
+	}
+	return &sampler{
+		modelIDAttribute:   cfg.ModelIDAttribute,
+		samplingPercentage: cfg.SamplingPercentage,
+		models:             models,
+		random:             rand.Float64,
+	}
+}
+
+func (s *sampler) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		resourceModelID := s.modelID(rl.Resource().Attributes(), "")
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				return !s.sample(s.modelID(lr.Attributes(), resourceModelID), lr.TraceID())
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+func (s *sampler) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		resourceModelID := s.modelID(rs.Resource().Attributes(), "")
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(span ptrace.Span) bool {
+				return !s.sample(s.modelID(span.Attributes(), resourceModelID), span.TraceID())
+			})
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+	if td.ResourceSpans().Len() == 0 {
+		return td, processorhelper.ErrSkipProcessingData
+	}
+	return td, nil
+}
+
+// modelID returns the model ID held by attrs, or fallback if they have none.
+func (s *sampler) modelID(attrs pcommon.Map, fallback string) string {
+	if v, ok := attrs.Get(s.modelIDAttribute); ok {
+		return v.AsString()
+	}
+	return fallback
+}
+
+// sample reports whether telemetry of modelID is kept. Telemetry of the same
+// trace gets the same decision, so that a sampled request is kept whole.
+func (s *sampler) sample(modelID string, traceID pcommon.TraceID) bool {
+	percentage, ok := s.models[modelID]
+	if !ok {
+		percentage = s.samplingPercentage
+	}
+	switch {
+	case percentage >= 100:
+		return true
+	case percentage <= 0:
+		return false
+	}
+
+	var r float64
+	if traceID.IsEmpty() {
+		r = s.random()
+	} else {
+		r = float64(binary.BigEndian.Uint64(traceID[8:])&randomnessMask) / (randomnessMask + 1)
+	}
+	return r*100 < percentage
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fiddlersamplerprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/fiddlersamplerprocessor/internal/metadata"
+)
+
+// traceID returns a trace ID whose randomness maps to r, in [0, 1).
+func traceID(r float64) pcommon.TraceID {
+	var id pcommon.TraceID
+	v := uint64(r * (randomnessMask + 1))
+	for i := 15; i >= 8; i-- {
+		id[i] = byte(v)
+		v >>= 8
+	}
+	return id
+}
+
+func TestSample(t *testing.T) {
+	s := newSampler(&Config{
+		ModelIDAttribute:   defaultModelIDAttribute,
+		SamplingPercentage: 50,
+		Models: []ModelConfig{
+			{ModelID: "all", SamplingPercentage: 100},
+			{ModelID: "none", SamplingPercentage: 0},
+			{ModelID: "tenth", SamplingPercentage: 10},
+		},
+	})
+	s.random = func() float64 { return 0.3 }
+
+	tests := []struct {
+		name    string
+		modelID string
+		traceID pcommon.TraceID
+		want    bool
+	}{
+		{name: "all", modelID: "all", traceID: traceID(0.99), want: true},
+		{name: "none", modelID: "none", traceID: traceID(0), want: false},
+		{name: "below model percentage", modelID: "tenth", traceID: traceID(0.05), want: true},
+		{name: "above model percentage", modelID: "tenth", traceID: traceID(0.2), want: false},
+		{name: "default percentage", modelID: "other", traceID: traceID(0.4), want: true},
+		{name: "no model", traceID: traceID(0.6), want: false},
+		{name: "random without trace ID", modelID: "other", want: true},
+		{name: "random above model percentage", modelID: "tenth", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.sample(tt.modelID, tt.traceID))
+		})
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	cfg := &Config{
+		ModelIDAttribute:   defaultModelIDAttribute,
+		SamplingPercentage: 100,
+		Models:             []ModelConfig{{ModelID: "credit-model", SamplingPercentage: 50}},
+	}
+	sink := new(consumertest.LogsSink)
+	p, err := createLogsProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(t.Context())) }()
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(defaultModelIDAttribute, "credit-model")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	kept := lrs.AppendEmpty()
+	kept.SetTraceID(traceID(0.1))
+	kept.Attributes().PutStr("prediction", "kept")
+	dropped := lrs.AppendEmpty()
+	dropped.SetTraceID(traceID(0.9))
+	dropped.Attributes().PutStr("prediction", "dropped")
+	other := lrs.AppendEmpty()
+	other.SetTraceID(traceID(0.9))
+	other.Attributes().PutStr(defaultModelIDAttribute, "fraud-model")
+	other.Attributes().PutStr("prediction", "other model")
+
+	droppedResource := ld.ResourceLogs().AppendEmpty()
+	droppedResource.Resource().Attributes().PutStr(defaultModelIDAttribute, "credit-model")
+	droppedResource.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().SetTraceID(traceID(0.7))
+
+	require.NoError(t, p.ConsumeLogs(t.Context(), ld))
+	require.Len(t, sink.AllLogs(), 1)
+	got := sink.AllLogs()[0]
+	require.Equal(t, 1, got.ResourceLogs().Len())
+	records := got.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	v, _ := records.At(0).Attributes().Get("prediction")
+	assert.Equal(t, "kept", v.Str())
+	v, _ = records.At(1).Attributes().Get("prediction")
+	assert.Equal(t, "other model", v.Str())
+}
+
+func TestProcessTraces(t *testing.T) {
+	cfg := &Config{
+		ModelIDAttribute:   defaultModelIDAttribute,
+		SamplingPercentage: 0,
+		Models:             []ModelConfig{{ModelID: "llm-app", SamplingPercentage: 20}},
+	}
+	sink := new(consumertest.TracesSink)
+	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(t.Context())) }()
+
+	newTraces := func(r float64) ptrace.Traces {
+		td := ptrace.NewTraces()
+		spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+		for _, modelID := range []string{"llm-app", "llm-app", "other"} {
+			span := spans.AppendEmpty()
+			span.SetTraceID(traceID(r))
+			span.Attributes().PutStr(defaultModelIDAttribute, modelID)
+		}
+		return td
+	}
+
+	require.NoError(t, p.ConsumeTraces(t.Context(), newTraces(0.1)))
+	require.NoError(t, p.ConsumeTraces(t.Context(), newTraces(0.5)))
+
+	require.Len(t, sink.AllTraces(), 1, "fully dropped batches are not forwarded")
+	assert.Equal(t, 2, sink.SpanCount(), "spans of a sampled trace are kept together")
+}